@@ -0,0 +1,39 @@
+// Command fixture prints one frame of a replay or protocol capture in the
+// hlt fixture format, for adding boards to scenario and golden corpora.
+//
+//	go run ./cmd/fixture -turn 40 -player 1 1234-42.hlt > golden/midgame.txt
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"hlt"
+	"replay"
+)
+
+func main() {
+	turn := flag.Int("turn", 0, "Turn to print")
+	player := flag.Int("player", 1, "Player the frame is seen from")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: fixture [-turn n] [-player n] replay.hlt|capture.txt\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	rep, err := replay.Load(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *turn < 0 || *turn >= len(rep.Frames) {
+		log.Fatalf("turn %d out of range 0-%d", *turn, len(rep.Frames)-1)
+	}
+	m := rep.Frames[*turn].Clone()
+	m.SetPlayer(hlt.PlayerID(*player))
+	fmt.Print(hlt.Fixture{Me: hlt.PlayerID(*player), Map: m}.String())
+}
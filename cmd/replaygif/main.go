@@ -0,0 +1,66 @@
+// Command replaygif exports an engine replay or protocol capture as an
+// animated GIF.
+//
+//	go run ./cmd/replaygif -scale 6 -delay 8 -o game.gif 1234-42.hlt
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"hlt"
+	"render"
+	"replay"
+)
+
+func main() {
+	out := flag.String("o", "", "Output file, defaults to the replay name with .gif")
+	scale := flag.Int("scale", render.DefaultScale, "Pixels per cell")
+	delay := flag.Int("delay", 10, "Delay between frames in hundredths of a second")
+	every := flag.Int("every", 1, "Only render every n-th turn")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: replaygif [-o out.gif] [-scale n] [-delay n] [-every n] replay.hlt|capture.txt\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 || *every < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	in := flag.Arg(0)
+	if *out == "" {
+		*out = strings.TrimSuffix(in, filepath.Ext(in)) + ".gif"
+	}
+
+	rep, err := replay.Load(in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var frames []hlt.GameMap
+	for f := 0; f < len(rep.Frames); f += *every {
+		frames = append(frames, rep.Frames[f])
+	}
+	if last := len(rep.Frames) - 1; last%*every != 0 {
+		frames = append(frames, rep.Frames[last])
+	}
+
+	fh, err := os.Create(*out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts := render.GIFOptions{
+		Options: render.Options{Scale: *scale},
+		Delay:   *delay,
+	}
+	if err := render.WriteGIF(fh, frames, opts); err != nil {
+		log.Fatal(err)
+	}
+	if err := fh.Close(); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Wrote %d frames to %s", len(frames), *out)
+}
@@ -0,0 +1,107 @@
+// Command replayhtml turns an engine replay into a single HTML file that
+// plays the game back in a browser without network access.
+//
+//	go run ./cmd/replayhtml -o game.html 1234-42.hlt
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"hlt"
+	"render"
+	"replay"
+)
+
+type viewerData struct {
+	Title       string
+	Width       int
+	Height      int
+	Names       []string
+	Colors      []string
+	Productions [][]int
+	// Owners and Strengths are [frame][y*Width+x].
+	Owners    [][]int
+	Strengths [][]int
+	// Territory, Strength and Production are [player-1][frame].
+	Territory  [][]int
+	Strength   [][]int
+	Production [][]int
+}
+
+func newViewerData(title string, rep *replay.Replay) viewerData {
+	players := rep.PlayerCount()
+	data := viewerData{
+		Title:       title,
+		Width:       rep.Width,
+		Height:      rep.Height,
+		Names:       rep.PlayerNames,
+		Productions: rep.Productions,
+		Owners:      make([][]int, len(rep.Frames)),
+		Strengths:   make([][]int, len(rep.Frames)),
+		Territory:   make([][]int, players),
+		Strength:    make([][]int, players),
+		Production:  make([][]int, players),
+	}
+	for p := 0; p <= players; p++ {
+		c := render.PlayerColor(hlt.PlayerID(p))
+		data.Colors = append(data.Colors, fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B))
+	}
+	for f, frame := range rep.Frames {
+		owners := make([]int, 0, rep.Width*rep.Height)
+		strengths := make([]int, 0, rep.Width*rep.Height)
+		for y := 0; y < frame.Height; y++ {
+			for x := 0; x < frame.Width; x++ {
+				owners = append(owners, int(frame.Contents[y][x].Owner))
+				strengths = append(strengths, frame.Contents[y][x].Strength)
+			}
+		}
+		data.Owners[f] = owners
+		data.Strengths[f] = strengths
+
+		stats := rep.Stats(f)
+		for p := 0; p < players; p++ {
+			data.Territory[p] = append(data.Territory[p], stats[p+1].Territory)
+			data.Strength[p] = append(data.Strength[p], stats[p+1].Strength)
+			data.Production[p] = append(data.Production[p], stats[p+1].Production)
+		}
+	}
+	return data
+}
+
+func main() {
+	out := flag.String("o", "", "Output file, defaults to the replay name with .html")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: replayhtml [-o out.html] replay.hlt\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	in := flag.Arg(0)
+	if *out == "" {
+		*out = strings.TrimSuffix(in, filepath.Ext(in)) + ".html"
+	}
+
+	rep, err := replay.Load(in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fh, err := os.Create(*out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := viewer.Execute(fh, newViewerData(filepath.Base(in), rep)); err != nil {
+		log.Fatal(err)
+	}
+	if err := fh.Close(); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Wrote %d frames to %s", len(rep.Frames), *out)
+}
@@ -0,0 +1,170 @@
+package main
+
+import "html/template"
+
+var viewer = template.Must(template.New("viewer").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { background: #1b1b1f; color: #ddd; font: 13px monospace; margin: 16px; }
+#layout { display: flex; gap: 16px; align-items: flex-start; }
+#board { image-rendering: pixelated; border: 1px solid #444; }
+#charts canvas { display: block; background: #111; border: 1px solid #333; margin-bottom: 8px; }
+#controls { margin: 8px 0; }
+#controls button { font: inherit; min-width: 2.5em; }
+#turn { width: 420px; vertical-align: middle; }
+table { border-collapse: collapse; margin-top: 8px; }
+td, th { padding: 2px 8px; text-align: right; }
+.swatch { display: inline-block; width: 10px; height: 10px; margin-right: 4px; }
+</style>
+</head>
+<body>
+<h3>{{.Title}}</h3>
+<div id="controls">
+<button id="first" title="First turn (Home)">|&lt;</button>
+<button id="prev" title="Step back (Left)">&lt;</button>
+<button id="play" title="Play/pause (Space)">&#9654;</button>
+<button id="next" title="Step forward (Right)">&gt;</button>
+<button id="last" title="Last turn (End)">&gt;|</button>
+<input id="turn" type="range" min="0" value="0">
+<span id="label"></span>
+<label>speed <select id="speed"><option value="400">slow</option><option value="150" selected>normal</option><option value="40">fast</option></select></label>
+</div>
+<div id="layout">
+<canvas id="board"></canvas>
+<div id="charts">
+<div>territory</div><canvas id="territory" width="360" height="110"></canvas>
+<div>strength</div><canvas id="strength" width="360" height="110"></canvas>
+<div>production</div><canvas id="production" width="360" height="110"></canvas>
+<table id="stats"></table>
+</div>
+</div>
+<script>
+const data = {{.}};
+const frames = data.Owners.length;
+const cell = Math.max(4, Math.min(16, Math.floor(640 / Math.max(data.Width, data.Height))));
+const board = document.getElementById("board");
+board.width = data.Width * cell;
+board.height = data.Height * cell;
+const ctx = board.getContext("2d");
+const slider = document.getElementById("turn");
+slider.max = frames - 1;
+let turn = 0, timer = null;
+
+let maxProduction = 1;
+data.Productions.forEach(row => row.forEach(p => { maxProduction = Math.max(maxProduction, p); }));
+
+function rgb(hex) {
+  return [1, 3, 5].map(i => parseInt(hex.substr(i, 2), 16));
+}
+const colors = data.Colors.map(rgb);
+
+function drawBoard() {
+  const owners = data.Owners[turn], strengths = data.Strengths[turn];
+  for (let y = 0; y < data.Height; y++) {
+    for (let x = 0; x < data.Width; x++) {
+      const i = y * data.Width + x, owner = owners[i], strength = strengths[i];
+      const level = 20 + Math.floor(80 * data.Productions[y][x] / maxProduction);
+      ctx.fillStyle = "rgb(" + level + "," + level + "," + (level + (level >> 2)) + ")";
+      ctx.fillRect(x * cell, y * cell, cell, cell);
+      let c;
+      if (owner === 0) {
+        const f = strength / 510;
+        c = colors[0].map(v => Math.floor(level * (1 - f) + v * f));
+      } else {
+        const f = 0.4 + 0.6 * strength / 255;
+        c = colors[owner].map(v => Math.floor(v * f));
+      }
+      ctx.fillStyle = "rgb(" + c.join(",") + ")";
+      ctx.fillRect(x * cell + 1, y * cell + 1, cell - 2, cell - 2);
+    }
+  }
+}
+
+function drawChart(id, series) {
+  const canvas = document.getElementById(id), g = canvas.getContext("2d");
+  g.clearRect(0, 0, canvas.width, canvas.height);
+  let max = 1;
+  series.forEach(s => s.forEach(v => { max = Math.max(max, v); }));
+  const sx = canvas.width / Math.max(1, frames - 1), sy = (canvas.height - 4) / max;
+  series.forEach((s, p) => {
+    g.strokeStyle = data.Colors[p + 1];
+    g.beginPath();
+    s.forEach((v, f) => {
+      const px = f * sx, py = canvas.height - 2 - v * sy;
+      if (f === 0) g.moveTo(px, py); else g.lineTo(px, py);
+    });
+    g.stroke();
+  });
+  g.strokeStyle = "#888";
+  g.beginPath();
+  g.moveTo(turn * sx, 0);
+  g.lineTo(turn * sx, canvas.height);
+  g.stroke();
+}
+
+function drawStats() {
+  let html = "<tr><th></th><th>cells</th><th>strength</th><th>production</th></tr>";
+  data.Names.forEach((name, p) => {
+    html += "<tr><td style='text-align:left'><span class='swatch' style='background:" + data.Colors[p + 1] + "'></span>" +
+      (p + 1) + " " + name.replace(/</g, "&lt;") + "</td><td>" + data.Territory[p][turn] + "</td><td>" +
+      data.Strength[p][turn] + "</td><td>" + data.Production[p][turn] + "</td></tr>";
+  });
+  document.getElementById("stats").innerHTML = html;
+}
+
+function show(t) {
+  turn = Math.max(0, Math.min(frames - 1, t));
+  slider.value = turn;
+  document.getElementById("label").textContent = "turn " + turn + " / " + (frames - 1);
+  drawBoard();
+  drawChart("territory", data.Territory);
+  drawChart("strength", data.Strength);
+  drawChart("production", data.Production);
+  drawStats();
+}
+
+function pause() {
+  clearInterval(timer);
+  timer = null;
+  document.getElementById("play").innerHTML = "&#9654;";
+}
+
+function play() {
+  if (turn >= frames - 1) show(0);
+  const delay = parseInt(document.getElementById("speed").value, 10);
+  timer = setInterval(() => {
+    if (turn >= frames - 1) { pause(); return; }
+    show(turn + 1);
+  }, delay);
+  document.getElementById("play").innerHTML = "&#10074;&#10074;";
+}
+
+function toggle() { if (timer) pause(); else play(); }
+
+document.getElementById("first").onclick = () => { pause(); show(0); };
+document.getElementById("prev").onclick = () => { pause(); show(turn - 1); };
+document.getElementById("play").onclick = toggle;
+document.getElementById("next").onclick = () => { pause(); show(turn + 1); };
+document.getElementById("last").onclick = () => { pause(); show(frames - 1); };
+document.getElementById("speed").onchange = () => { if (timer) { pause(); play(); } };
+slider.oninput = () => { pause(); show(parseInt(slider.value, 10)); };
+document.addEventListener("keydown", e => {
+  if (e.target.tagName === "SELECT") return;
+  switch (e.key) {
+  case " ": toggle(); break;
+  case "ArrowLeft": pause(); show(turn - 1); break;
+  case "ArrowRight": pause(); show(turn + 1); break;
+  case "Home": pause(); show(0); break;
+  case "End": pause(); show(frames - 1); break;
+  default: return;
+  }
+  e.preventDefault();
+});
+show(0);
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,229 @@
+// Command replaytui plays back an engine replay or a protocol capture in the
+// terminal.
+//
+//	go run ./cmd/replaytui 1234-42.hlt
+//
+// Keys: space play/pause, left/right step, g<turn><enter> jump to a turn,
+// home/end first/last turn, +/- speed, q quit.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"hlt"
+	"replay"
+)
+
+var delays = []time.Duration{
+	1000 * time.Millisecond,
+	500 * time.Millisecond,
+	250 * time.Millisecond,
+	120 * time.Millisecond,
+	60 * time.Millisecond,
+	30 * time.Millisecond,
+}
+
+type player struct {
+	rep     *replay.Replay
+	turn    int
+	playing bool
+	speed   int
+	jumping bool
+	jumpTo  string
+}
+
+func (p *player) last() int {
+	return len(p.rep.Frames) - 1
+}
+
+func (p *player) show(turn int) {
+	if turn < 0 {
+		turn = 0
+	}
+	if turn > p.last() {
+		turn = p.last()
+	}
+	p.turn = turn
+}
+
+// handle applies a key press and reports whether the player should quit.
+func (p *player) handle(k keyPress) bool {
+	if p.jumping {
+		switch {
+		case k.key == keyEnter:
+			if turn, err := strconv.Atoi(p.jumpTo); err == nil {
+				p.show(turn)
+			}
+			p.jumping = false
+		case k.key == keyEscape:
+			p.jumping = false
+		case k.key == keyBackspace && len(p.jumpTo) > 0:
+			p.jumpTo = p.jumpTo[:len(p.jumpTo)-1]
+		case k.key == keyRune && k.r >= '0' && k.r <= '9':
+			p.jumpTo += string(k.r)
+		}
+		return false
+	}
+
+	switch k.key {
+	case keyLeft:
+		p.playing = false
+		p.show(p.turn - 1)
+	case keyRight:
+		p.playing = false
+		p.show(p.turn + 1)
+	case keyHome:
+		p.show(0)
+	case keyEnd:
+		p.show(p.last())
+	case keyRune:
+		switch k.r {
+		case 'q', 'Q', 3:
+			return true
+		case ' ', 'p':
+			if !p.playing && p.turn == p.last() {
+				p.show(0)
+			}
+			p.playing = !p.playing
+		case 'h', 'b':
+			p.playing = false
+			p.show(p.turn - 1)
+		case 'l', 'n':
+			p.playing = false
+			p.show(p.turn + 1)
+		case '<':
+			p.show(0)
+		case '>':
+			p.show(p.last())
+		case 'g':
+			p.playing = false
+			p.jumping = true
+			p.jumpTo = ""
+		case '+', '=':
+			if p.speed < len(delays)-1 {
+				p.speed++
+			}
+		case '-':
+			if p.speed > 0 {
+				p.speed--
+			}
+		}
+	}
+	return false
+}
+
+func (p *player) panel() []string {
+	state := "paused"
+	if p.playing {
+		state = "playing"
+	}
+	lines := []string{
+		fmt.Sprintf("turn %d/%d  %s  %v/turn", p.turn, p.last(), state, delays[p.speed]),
+		"",
+		fmt.Sprintf("     %-20s %6s %8s %5s", "player", "cells", "strength", "prod"),
+	}
+	stats := p.rep.Stats(p.turn)
+	for id := 1; id < len(stats); id++ {
+		s := stats[id]
+		name := p.rep.Name(hlt.PlayerID(id))
+		if len(name) > 17 {
+			name = name[:17]
+		}
+		lines = append(lines, fmt.Sprintf("%s  \x1b[0m %d %-17s %6d %8d %5d",
+			hlt.ANSIStyle(hlt.PlayerID(id)), id, name, s.Territory, s.Strength, s.Production))
+	}
+	lines = append(lines,
+		"",
+		"space play/pause  left/right step",
+		"g jump  home/end first/last",
+		"+/- speed  q quit",
+	)
+	if p.jumping {
+		lines = append(lines, "", "jump to turn: "+p.jumpTo+"_")
+	}
+	return lines
+}
+
+func (p *player) draw() {
+	board := strings.Split(strings.TrimRight(p.rep.Frames[p.turn].Render(hlt.RenderOptions{Color: true}), "\n"), "\n")
+	panel := p.panel()
+	boardWidth := 3 + 3*p.rep.Width
+	var buf bytes.Buffer
+	buf.WriteString("\x1b[H")
+	for i := 0; i < len(board) || i < len(panel); i++ {
+		if i < len(board) {
+			buf.WriteString(board[i])
+		} else {
+			buf.WriteString(strings.Repeat(" ", boardWidth))
+		}
+		if i < len(panel) {
+			buf.WriteString("   ")
+			buf.WriteString(panel[i])
+		}
+		buf.WriteString("\x1b[K\n")
+	}
+	buf.WriteString("\x1b[J")
+	os.Stdout.Write(buf.Bytes())
+}
+
+func main() {
+	start := flag.Int("turn", 0, "Turn to start at")
+	autoplay := flag.Bool("play", false, "Start playing immediately")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: replaytui [-turn n] [-play] replay.hlt|capture.txt\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	rep, err := replay.Load(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	restore, err := rawMode()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer restore()
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+
+	p := &player{rep: rep, playing: *autoplay, speed: 2}
+	p.show(*start)
+	keys := make(chan keyPress)
+	go readKeys(keys)
+
+	fmt.Print("\x1b[2J")
+	for {
+		p.draw()
+		var tick <-chan time.Time
+		if p.playing {
+			tick = time.After(delays[p.speed])
+		}
+		select {
+		case k, ok := <-keys:
+			if !ok || p.handle(k) {
+				return
+			}
+		case <-tick:
+			if p.turn >= p.last() {
+				p.playing = false
+			} else {
+				p.show(p.turn + 1)
+			}
+		case <-interrupts:
+			return
+		}
+	}
+}
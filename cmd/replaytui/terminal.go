@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+type key int
+
+const (
+	keyRune key = iota
+	keyLeft
+	keyRight
+	keyHome
+	keyEnd
+	keyEnter
+	keyEscape
+	keyBackspace
+)
+
+type keyPress struct {
+	key key
+	r   rune
+}
+
+// stty runs stty against the controlling terminal, since os.Stdin is the
+// terminal we want to configure.
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// rawMode switches the terminal to unbuffered input without echo and returns
+// a function that restores the previous settings.
+func rawMode() (func(), error) {
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("standard input is not a terminal: %v", err)
+	}
+	if _, err := stty("-icanon", "-echo", "min", "1"); err != nil {
+		return nil, err
+	}
+	fmt.Print("\x1b[?25l")
+	return func() {
+		fmt.Print("\x1b[?25h\x1b[0m\n")
+		stty(saved)
+	}, nil
+}
+
+// readKeys decodes key presses from standard input, including the escape
+// sequences terminals send for arrow, Home and End keys.
+func readKeys(keys chan<- keyPress) {
+	in := bufio.NewReader(os.Stdin)
+	for {
+		r, _, err := in.ReadRune()
+		if err != nil {
+			close(keys)
+			return
+		}
+		switch r {
+		case '\r', '\n':
+			keys <- keyPress{key: keyEnter}
+		case 127, '\b':
+			keys <- keyPress{key: keyBackspace}
+		case 0x1b:
+			if in.Buffered() == 0 {
+				keys <- keyPress{key: keyEscape}
+				continue
+			}
+			seq, _ := in.ReadByte()
+			if seq != '[' && seq != 'O' {
+				keys <- keyPress{key: keyEscape}
+				continue
+			}
+			code, _ := in.ReadByte()
+			switch code {
+			case 'C':
+				keys <- keyPress{key: keyRight}
+			case 'D':
+				keys <- keyPress{key: keyLeft}
+			case 'H':
+				keys <- keyPress{key: keyHome}
+			case 'F':
+				keys <- keyPress{key: keyEnd}
+			case '1', '7':
+				in.ReadByte()
+				keys <- keyPress{key: keyHome}
+			case '4', '8':
+				in.ReadByte()
+				keys <- keyPress{key: keyEnd}
+			}
+		default:
+			keys <- keyPress{key: keyRune, r: r}
+		}
+	}
+}
@@ -0,0 +1,162 @@
+// Command sprt decides whether a new bot build is stronger than an old one.
+// It plays the two against each other with the halite environment, each
+// map seed twice with the spawns swapped, and runs a sequential probability
+// ratio test on the results, stopping as soon as it accepts either
+// hypothesis.
+//
+//	go build -o mybot-new MyBot.go
+//	go run ./cmd/sprt -new ./mybot-new -old ./mybot -elo0 0 -elo1 30
+//
+// H0 is that the new build is elo0 stronger than the old one, H1 that it
+// is elo1 stronger; accepting H1 means the new build is better.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+type game struct {
+	seed int
+	// swapped games give the new build the second spawn.
+	swapped bool
+	newWon  bool
+	err     error
+}
+
+func main() {
+	newBot := flag.String("new", "./mybot-new", "Command running the new build")
+	oldBot := flag.String("old", "./mybot", "Command running the old build")
+	halite := flag.String("halite", "./halite", "Path to the halite environment")
+	dims := flag.String("d", "30 30", "Map dimensions")
+	elo0 := flag.Float64("elo0", 0, "Elo difference under H0")
+	elo1 := flag.Float64("elo1", 30, "Elo difference under H1")
+	alpha := flag.Float64("alpha", 0.05, "Chance of accepting H1 when H0 holds")
+	beta := flag.Float64("beta", 0.05, "Chance of accepting H0 when H1 holds")
+	maxGames := flag.Int("max", 2000, "Stop undecided after this many games")
+	seed := flag.Int("seed", 1, "First map seed")
+	jobs := flag.Int("j", runtime.NumCPU()/2, "Seeds played in parallel")
+	keep := flag.Bool("keep", false, "Keep the replay files")
+	flag.Parse()
+	if *elo1 <= *elo0 {
+		fmt.Fprintln(os.Stderr, "-elo1 must be greater than -elo0")
+		os.Exit(2)
+	}
+	if *jobs < 1 {
+		*jobs = 1
+	}
+
+	play := func(seed int, swapped bool) game {
+		bots := []string{*newBot, *oldBot}
+		if swapped {
+			bots[0], bots[1] = bots[1], bots[0]
+		}
+		ranks, err := runGame(*halite, *dims, seed, bots, *keep)
+		g := game{seed: seed, swapped: swapped, err: err}
+		if err == nil {
+			g.newWon = ranks[0] == 1
+			if swapped {
+				g.newWon = ranks[1] == 1
+			}
+		}
+		return g
+	}
+
+	// Worker i plays every jobs-th seed and results are read back round
+	// robin, so the sequence of results does not depend on the parallelism.
+	next := make([]chan int, *jobs)
+	results := make([]chan [2]game, *jobs)
+	s := *seed
+	for i := range next {
+		next[i] = make(chan int, 1)
+		results[i] = make(chan [2]game, 1)
+		go func(in chan int, out chan [2]game) {
+			for s := range in {
+				out <- [2]game{play(s, false), play(s, true)}
+			}
+		}(next[i], results[i])
+		next[i] <- s
+		s++
+	}
+
+	test := NewSPRT(*elo0, *elo1, *alpha, *beta)
+	fmt.Printf("H0: elo %+.0f  H1: elo %+.0f  bounds [%.2f, %.2f]\n", *elo0, *elo1, test.Lower, test.Upper)
+	for i := 0; test.Result() == 0 && test.Wins+test.Losses < *maxGames; i = (i + 1) % *jobs {
+		pair := <-results[i]
+		next[i] <- s
+		s++
+		for _, g := range pair {
+			if g.err != nil {
+				fmt.Fprintf(os.Stderr, "seed %d: %v\n", g.seed, g.err)
+				os.Exit(1)
+			}
+			test.Add(g.newWon)
+		}
+		fmt.Printf("seed %-6d %s %s  %4d-%-4d  elo %+6.1f  llr %6.2f\n",
+			pair[0].seed, outcome(pair[0].newWon), outcome(pair[1].newWon), test.Wins, test.Losses, test.Elo(), test.LLR())
+	}
+
+	switch test.Result() {
+	case 1:
+		fmt.Println("H1 accepted: the new build is better")
+	case -1:
+		fmt.Println("H0 accepted: the new build is not better")
+	default:
+		fmt.Printf("undecided after %d games\n", test.Wins+test.Losses)
+		os.Exit(1)
+	}
+}
+
+func outcome(won bool) string {
+	if won {
+		return "W"
+	}
+	return "L"
+}
+
+// runGame plays one game in quiet mode and returns each bot's rank.
+func runGame(halite, dims string, seed int, bots []string, keep bool) ([]int, error) {
+	args := append([]string{"-q", "-d", dims, "-s", strconv.Itoa(seed)}, bots...)
+	out, err := exec.Command(halite, args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", halite, err)
+	}
+
+	// Quiet output names the replay and seed, then lists "player rank"
+	// for every player.
+	var replay string
+	ranks := make([]int, len(bots))
+	found := 0
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 2 && strings.HasSuffix(fields[0], ".hlt") {
+			replay = fields[0]
+			continue
+		}
+		if replay == "" || len(fields) != 2 {
+			continue
+		}
+		player, err1 := strconv.Atoi(fields[0])
+		rank, err2 := strconv.Atoi(fields[1])
+		if err1 != nil || err2 != nil || player < 1 || player > len(bots) {
+			continue
+		}
+		ranks[player-1] = rank
+		found++
+	}
+	if found != len(bots) {
+		return nil, fmt.Errorf("unexpected halite output:\n%s", out)
+	}
+	if !keep {
+		os.Remove(replay)
+	}
+	return ranks, nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import "math"
+
+// SPRT is a sequential probability ratio test between two hypotheses about
+// the Elo difference of A over B, with every game scored as a win or loss.
+type SPRT struct {
+	// Scores expected under H0 (elo0) and H1 (elo1).
+	p0, p1 float64
+	// Bounds on the log-likelihood ratio for accepting H0 and H1.
+	Lower, Upper float64
+	Wins, Losses int
+}
+
+func NewSPRT(elo0, elo1, alpha, beta float64) *SPRT {
+	return &SPRT{
+		p0:    eloScore(elo0),
+		p1:    eloScore(elo1),
+		Lower: math.Log(beta / (1 - alpha)),
+		Upper: math.Log((1 - beta) / alpha),
+	}
+}
+
+func (s *SPRT) Add(won bool) {
+	if won {
+		s.Wins++
+	} else {
+		s.Losses++
+	}
+}
+
+func (s *SPRT) LLR() float64 {
+	return float64(s.Wins)*math.Log(s.p1/s.p0) + float64(s.Losses)*math.Log((1-s.p1)/(1-s.p0))
+}
+
+// Result is 1 once H1 is accepted, -1 once H0 is, and 0 while undecided.
+func (s *SPRT) Result() int {
+	switch llr := s.LLR(); {
+	case llr >= s.Upper:
+		return 1
+	case llr <= s.Lower:
+		return -1
+	}
+	return 0
+}
+
+// Elo estimates the difference from the score so far.
+func (s *SPRT) Elo() float64 {
+	n := float64(s.Wins + s.Losses)
+	if n == 0 {
+		return 0
+	}
+	score := math.Min(math.Max(float64(s.Wins)/n, 0.5/n), 1-0.5/n)
+	return -400 * math.Log10(1/score-1)
+}
+
+func eloScore(elo float64) float64 {
+	return 1 / (1 + math.Pow(10, -elo/400))
+}
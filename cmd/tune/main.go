@@ -0,0 +1,391 @@
+// Command tune evolves the parameters a strategy reads with a genetic
+// algorithm. Every generation each parameter set plays games on generated
+// maps against other members of the population and against the baseline
+// bots, and the ones with the best scores breed the next generation. A win
+// scores one and a tie on territory at the turn limit half.
+//
+//	go run ./cmd/tune -strategy heat -pop 16 -gens 20 -checkpoint tune.json -out best.json
+//
+// The population is saved to the checkpoint after every generation and a
+// later run with the same checkpoint carries on from there. The best
+// parameters are written as JSON that MyBot reads with -params.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"os"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"bot"
+	"sim"
+)
+
+type Individual struct {
+	Params bot.Params `json:"params"`
+	Wins   int        `json:"wins"`
+	Ties   int        `json:"ties"`
+	Games  int        `json:"games"`
+}
+
+// Score is the share of games won, counting ties as half a win.
+func (ind Individual) Score() float64 {
+	if ind.Games == 0 {
+		return 0
+	}
+	return (float64(ind.Wins) + float64(ind.Ties)/2) / float64(ind.Games)
+}
+
+func (ind *Individual) add(o outcome) {
+	ind.Games++
+	switch o {
+	case won:
+		ind.Wins++
+	case tied:
+		ind.Ties++
+	}
+}
+
+func (ind Individual) String() string {
+	return fmt.Sprintf("%.2f (%d won, %d tied of %d)", ind.Score(), ind.Wins, ind.Ties, ind.Games)
+}
+
+type Checkpoint struct {
+	Strategy   string       `json:"strategy"`
+	Generation int          `json:"generation"`
+	Seed       int64        `json:"seed"`
+	Population []Individual `json:"population"`
+}
+
+type opponent struct {
+	name     string
+	strategy string
+	params   bot.Params
+}
+
+var baselines = []opponent{
+	{"mybot", "mybot", bot.DefaultParams()},
+	{"random", "random", bot.DefaultParams()},
+}
+
+type outcome int
+
+const (
+	lost outcome = iota
+	tied
+	won
+)
+
+type match struct {
+	individual int
+	opp        opponent
+	size       int
+	seed       int64
+	swap       bool
+	outcome    outcome
+}
+
+var (
+	sizes []int
+	turns int
+	// strategy is the strategy being tuned and tuned the keys of the
+	// parameters it reads, the only ones evolved.
+	strategy string
+	tuned    = make(map[string]bool)
+)
+
+func main() {
+	flag.StringVar(&strategy, "strategy", "mybot", "Strategy to tune, one of "+strings.Join(bot.StrategyNames(), ", "))
+	pop := flag.Int("pop", 12, "Population size")
+	gens := flag.Int("gens", 10, "Generations to run")
+	games := flag.Int("games", 2, "Maps per opponent per generation, each played from both seats")
+	peers := flag.Int("peers", 2, "Population members each individual plays per generation")
+	elite := flag.Int("elite", 2, "Best individuals copied unchanged into the next generation")
+	rate := flag.Float64("mutation", 0.3, "Chance of mutating each parameter")
+	sizeList := flag.String("sizes", "20,24,30", "Comma separated map sizes; maps are square")
+	flag.IntVar(&turns, "turns", 0, "Turn limit per game, 0 for the engine's limit")
+	seed := flag.Int64("seed", 1, "Seed for maps and evolution")
+	jobs := flag.Int("j", runtime.NumCPU(), "Games played in parallel")
+	checkpoint := flag.String("checkpoint", "tune.json", "Population checkpoint, resumed if it exists")
+	out := flag.String("out", "best.json", "Where to write the best parameters")
+	final := flag.Int("final", 10, "Maps per baseline in the final evaluation")
+	flag.Parse()
+	log.SetOutput(ioutil.Discard)
+
+	keys, err := bot.StrategyParams(strategy)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	for _, k := range keys {
+		tuned[k] = true
+	}
+	for _, s := range strings.Split(*sizeList, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || n < 2 || n%2 != 0 {
+			fmt.Fprintf(os.Stderr, "bad map size %q, sizes must be even\n", s)
+			os.Exit(2)
+		}
+		sizes = append(sizes, n)
+	}
+
+	cp, err := loadCheckpoint(*checkpoint)
+	switch {
+	case err == nil && cp.Strategy != strategy:
+		fmt.Fprintf(os.Stderr, "%s tunes %s, not %s\n", *checkpoint, cp.Strategy, strategy)
+		os.Exit(2)
+	case err == nil:
+		fmt.Printf("resuming %s at generation %d\n", *checkpoint, cp.Generation)
+	case os.IsNotExist(err):
+		cp = Checkpoint{Strategy: strategy, Seed: *seed, Population: initialPopulation(*pop, rand.New(rand.NewSource(*seed)))}
+	default:
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	for cp.Generation < *gens {
+		rng := rand.New(rand.NewSource(cp.Seed + int64(cp.Generation)))
+		// The checkpoint holds the scored population of the last finished
+		// generation, which breeds this one.
+		if cp.Generation > 0 {
+			cp.Population = breed(cp.Population, *elite, *rate, rng)
+		}
+		population := cp.Population
+		var matches []match
+		for i := range population {
+			for _, opp := range baselines {
+				matches = append(matches, pairings(i, opp, *games, rng)...)
+			}
+			for _, j := range rng.Perm(len(population))[:min(*peers+1, len(population))] {
+				if j == i || *peers == 0 {
+					continue
+				}
+				opp := opponent{fmt.Sprintf("#%d", j), strategy, population[j].Params}
+				matches = append(matches, pairings(i, opp, 1, rng)...)
+			}
+		}
+		play(matches, population, *jobs)
+		for _, m := range matches {
+			population[m.individual].add(m.outcome)
+		}
+
+		sort.SliceStable(population, func(a, b int) bool { return population[a].Score() > population[b].Score() })
+		best := population[0]
+		cp.Generation++
+		fmt.Printf("generation %d: best %v %s\n", cp.Generation, best, compact(best.Params))
+		if err := saveCheckpoint(*checkpoint, cp); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	sort.SliceStable(cp.Population, func(a, b int) bool { return cp.Population[a].Score() > cp.Population[b].Score() })
+	best := cp.Population[0].Params
+	report(best, *final, cp.Seed, *jobs)
+	data, _ := json.MarshalIndent(best, "", "  ")
+	if err := ioutil.WriteFile(*out, append(data, '\n'), 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("wrote %s\n", *out)
+}
+
+// pairings plays individual i against opp on n fresh maps, from both seats.
+func pairings(i int, opp opponent, n int, rng *rand.Rand) (ms []match) {
+	for k := 0; k < n; k++ {
+		size := sizes[rng.Intn(len(sizes))]
+		seed := rng.Int63()
+		for _, swap := range []bool{false, true} {
+			ms = append(ms, match{individual: i, opp: opp, size: size, seed: seed, swap: swap})
+		}
+	}
+	return ms
+}
+
+func play(matches []match, population []Individual, jobs int) {
+	var wg sync.WaitGroup
+	next := make(chan int)
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				m := &matches[i]
+				m.outcome = playMatch(population[m.individual].Params, m.opp, m.size, m.seed, m.swap)
+			}
+		}()
+	}
+	for i := range matches {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
+
+// playMatch plays a bot with params against opp on the generated map. A game
+// that reaches the turn limit with both holding the same territory is a tie.
+func playMatch(params bot.Params, opp opponent, size int, seed int64, swap bool) outcome {
+	m, err := sim.Generate(size, size, 2, seed)
+	if err != nil {
+		panic(err)
+	}
+	players := []sim.Player{newBot(strategy, params, seed), newBot(opp.strategy, opp.params, seed+1)}
+	if swap {
+		players[0], players[1] = players[1], players[0]
+	}
+	res := sim.Game{Map: m, Players: players, Turns: turns}.Play()
+	me := 0
+	if swap {
+		me = 1
+	}
+	switch {
+	case res.Territory[0] == res.Territory[1]:
+		return tied
+	case res.Rank[me] == 1:
+		return won
+	}
+	return lost
+}
+
+func newBot(strategy string, params bot.Params, seed int64) *bot.Bot {
+	s, err := bot.NewStrategy(strategy)
+	if err != nil {
+		panic(err)
+	}
+	b := bot.New(s, seed)
+	b.SetParams(params)
+	b.SetWorkers(1)
+	return b
+}
+
+func initialPopulation(n int, rng *rand.Rand) []Individual {
+	population := []Individual{{Params: bot.DefaultParams()}}
+	for len(population) < n {
+		population = append(population, Individual{Params: mutate(bot.DefaultParams(), 1, rng)})
+	}
+	return population
+}
+
+// breed keeps the elite and fills the rest of the population with mutated
+// children of parents picked by tournament.
+func breed(ranked []Individual, elite int, rate float64, rng *rand.Rand) []Individual {
+	next := make([]Individual, 0, len(ranked))
+	for i := 0; i < elite && i < len(ranked); i++ {
+		next = append(next, Individual{Params: ranked[i].Params})
+	}
+	pick := func() bot.Params {
+		a, b := rng.Intn(len(ranked)), rng.Intn(len(ranked))
+		if ranked[b].Score() > ranked[a].Score() {
+			a = b
+		}
+		return ranked[a].Params
+	}
+	for len(next) < len(ranked) {
+		child := crossover(pick(), pick(), rng)
+		next = append(next, Individual{Params: mutate(child, rate, rng)})
+	}
+	return next
+}
+
+// isTuned reports whether field i of Params is read by the tuned strategy.
+func isTuned(v reflect.Value, i int) bool {
+	return tuned[strings.Split(v.Type().Field(i).Tag.Get("json"), ",")[0]]
+}
+
+func crossover(a, b bot.Params, rng *rand.Rand) bot.Params {
+	child := a
+	cv, bv := reflect.ValueOf(&child).Elem(), reflect.ValueOf(b)
+	for i := 0; i < cv.NumField(); i++ {
+		if isTuned(cv, i) && rng.Intn(2) == 0 {
+			cv.Field(i).Set(bv.Field(i))
+		}
+	}
+	return child
+}
+
+// mutate nudges each tuned parameter with the given chance by a normally
+// distributed step proportional to its value, never below zero.
+func mutate(p bot.Params, rate float64, rng *rand.Rand) bot.Params {
+	v := reflect.ValueOf(&p).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if !isTuned(v, i) || rng.Float64() >= rate {
+			continue
+		}
+		f := v.Field(i)
+		switch f.Kind() {
+		case reflect.Int:
+			n := f.Int()
+			step := int64(rng.NormFloat64() * float64(max(n/3, 1)))
+			if step == 0 {
+				step = int64(rng.Intn(2)*2 - 1)
+			}
+			f.SetInt(max(n+step, 0))
+		case reflect.Float64:
+			x := f.Float()
+			f.SetFloat(max(x+rng.NormFloat64()*max(x/3, 0.1), 0))
+		}
+	}
+	return p
+}
+
+// report plays the params against each baseline on fresh maps.
+func report(params bot.Params, n int, seed int64, jobs int) {
+	rng := rand.New(rand.NewSource(seed - 1))
+	population := []Individual{{Params: params}}
+	fmt.Printf("best: %s\n", compact(params))
+	total := Individual{}
+	for _, opp := range baselines {
+		ms := pairings(0, opp, n, rng)
+		play(ms, population, jobs)
+		var vs Individual
+		for _, m := range ms {
+			vs.add(m.outcome)
+			total.add(m.outcome)
+		}
+		fmt.Printf("  vs %-8s %v\n", opp.name, vs)
+	}
+	fmt.Printf("  overall     %v\n", total)
+}
+
+func compact(p bot.Params) string {
+	data, _ := json.Marshal(p)
+	return string(data)
+}
+
+func loadCheckpoint(path string) (Checkpoint, error) {
+	var cp Checkpoint
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return cp, err
+	}
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return cp, fmt.Errorf("%s: %v", path, err)
+	}
+	if len(cp.Population) == 0 {
+		return cp, fmt.Errorf("%s: empty population", path)
+	}
+	return cp, nil
+}
+
+// saveCheckpoint writes through a temporary file so an interrupted run
+// never leaves a truncated checkpoint behind.
+func saveCheckpoint(path string, cp Checkpoint) error {
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -1 +1 @@
-.\halite.exe -d "30 30" "go run MyBot.go" "go run RandomBot.go"
+.\halite.exe -d "30 30" "go run MyBot.go" "go run MyBot.go -strategy random"
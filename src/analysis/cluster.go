@@ -0,0 +1,240 @@
+package analysis
+
+import (
+	"hlt"
+	"sort"
+)
+
+// Cluster is a connected patch of neutral sites with high production.
+type Cluster struct {
+	Sites                []hlt.Location
+	Production, Strength int
+	// Distance is the number of steps from our territory to the cluster.
+	Distance int
+	// Turns estimates how long taking the whole cluster takes: the travel
+	// plus the turns our production needs to pay for its strength.
+	Turns float64
+	// dist holds the steps from every site to the nearest site of the
+	// cluster.
+	dist [][]int
+}
+
+// Value is the production the cluster adds per turn spent taking it.
+func (c *Cluster) Value() float64 {
+	return float64(c.Production) / c.Turns
+}
+
+// FindClusters finds the clusters of neutral sites whose production is in
+// the top percent of the map's neutral land, costed for the player the map
+// is seen from and sorted by value, best first. A percent below 0 or above
+// 100 counts as 0 or 100.
+func FindClusters(m hlt.GameMap, percent int) []*Cluster {
+	var productions []int
+	production := 0
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			site := m.Contents[y][x]
+			if site.IsNeutral() {
+				productions = append(productions, site.Production)
+			}
+			if site.IsMine() {
+				production += site.Production
+			}
+		}
+	}
+	if len(productions) == 0 {
+		return nil
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(productions)))
+	k := len(productions) * percent / 100
+	if k < 0 {
+		k = 0
+	}
+	if k >= len(productions) {
+		k = len(productions) - 1
+	}
+	threshold := productions[k]
+	if threshold < 1 {
+		threshold = 1
+	}
+	if production < 1 {
+		production = 1
+	}
+
+	rich := func(loc hlt.Location) bool {
+		site := m.Contents[loc.Y][loc.X]
+		return site.IsNeutral() && site.Production >= threshold
+	}
+	seen := make(map[hlt.Location]bool)
+	var clusters []*Cluster
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			start := hlt.NewLocation(x, y)
+			if seen[start] || !rich(start) {
+				continue
+			}
+			c := &Cluster{}
+			seen[start] = true
+			queue := []hlt.Location{start}
+			for len(queue) > 0 {
+				loc := queue[0]
+				queue = queue[1:]
+				site := m.Contents[loc.Y][loc.X]
+				c.Sites = append(c.Sites, loc)
+				c.Production += site.Production
+				c.Strength += site.Strength
+				for _, d := range hlt.CARDINALS {
+					next := m.GetLocation(loc, d)
+					if !seen[next] && rich(next) {
+						seen[next] = true
+						queue = append(queue, next)
+					}
+				}
+			}
+			c.dist = distances(m, c.Sites)
+			c.Distance = -1
+			for yy := 0; yy < m.Height; yy++ {
+				for xx := 0; xx < m.Width; xx++ {
+					if m.Contents[yy][xx].IsMine() && (c.Distance < 0 || c.dist[yy][xx] < c.Distance) {
+						c.Distance = c.dist[yy][xx]
+					}
+				}
+			}
+			if c.Distance < 0 {
+				c.Distance = 0
+			}
+			c.Turns = float64(c.Distance) + float64(c.Strength)/float64(production) + 1
+			clusters = append(clusters, c)
+		}
+	}
+	sort.SliceStable(clusters, func(i, j int) bool { return clusters[i].Value() > clusters[j].Value() })
+	return clusters
+}
+
+// distances returns the steps from every site of m to the nearest source.
+func distances(m hlt.GameMap, sources []hlt.Location) [][]int {
+	dist := make([][]int, m.Height)
+	for y := range dist {
+		dist[y] = make([]int, m.Width)
+		for x := range dist[y] {
+			dist[y][x] = -1
+		}
+	}
+	queue := make([]hlt.Location, 0, len(sources))
+	for _, loc := range sources {
+		dist[loc.Y][loc.X] = 0
+		queue = append(queue, loc)
+	}
+	for len(queue) > 0 {
+		loc := queue[0]
+		queue = queue[1:]
+		for _, d := range hlt.CARDINALS {
+			next := m.GetLocation(loc, d)
+			if dist[next.Y][next.X] < 0 {
+				dist[next.Y][next.X] = dist[loc.Y][loc.X] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+	return dist
+}
+
+// Targets assigns every section of our border a cluster to expand towards
+// and every interior site the target of its nearest border site.
+type Targets struct {
+	Clusters []*Cluster
+	// target is the index into Clusters for each of our sites, -1 if none.
+	target [][]int
+}
+
+// AssignTargets gives each border site the cluster that pays best from
+// there: the most production per turn of travel from that site plus the
+// turns to pay for the cluster's strength. Only the best max clusters are
+// considered, none if max is not positive.
+func AssignTargets(m hlt.GameMap, clusters []*Cluster, max int) *Targets {
+	if max < 0 {
+		max = 0
+	}
+	if len(clusters) > max {
+		clusters = clusters[:max]
+	}
+	t := &Targets{Clusters: clusters, target: make([][]int, m.Height)}
+	for y := range t.target {
+		t.target[y] = make([]int, m.Width)
+		for x := range t.target[y] {
+			t.target[y][x] = -1
+		}
+	}
+	if len(clusters) == 0 {
+		return t
+	}
+
+	var queue []hlt.Location
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			loc := hlt.NewLocation(x, y)
+			if !m.Contents[y][x].IsMine() || !onBorder(m, loc) {
+				continue
+			}
+			best, bestScore := -1, 0.0
+			for i, c := range clusters {
+				d := c.dist[y][x]
+				score := float64(c.Production) / (c.Turns + float64(d-c.Distance))
+				if best < 0 || score > bestScore {
+					best, bestScore = i, score
+				}
+			}
+			t.target[y][x] = best
+			queue = append(queue, loc)
+		}
+	}
+
+	// The interior takes the targets of the nearest border sites.
+	for len(queue) > 0 {
+		loc := queue[0]
+		queue = queue[1:]
+		for _, d := range hlt.CARDINALS {
+			next := m.GetLocation(loc, d)
+			if m.Contents[next.Y][next.X].IsMine() && t.target[next.Y][next.X] < 0 {
+				t.target[next.Y][next.X] = t.target[loc.Y][loc.X]
+				queue = append(queue, next)
+			}
+		}
+	}
+	return t
+}
+
+func onBorder(m hlt.GameMap, loc hlt.Location) bool {
+	for _, d := range hlt.CARDINALS {
+		if !m.GetSite(loc, d).IsMine() {
+			return true
+		}
+	}
+	return false
+}
+
+// Target returns the cluster assigned to one of our sites.
+func (t *Targets) Target(loc hlt.Location) (*Cluster, bool) {
+	i := t.target[loc.Y][loc.X]
+	if i < 0 {
+		return nil, false
+	}
+	return t.Clusters[i], true
+}
+
+// Directions lists the moves that take a piece on one of our sites a step
+// closer to its target.
+func (t *Targets) Directions(m hlt.GameMap, loc hlt.Location) (d []hlt.Direction) {
+	c, ok := t.Target(loc)
+	if !ok {
+		return nil
+	}
+	here := c.dist[loc.Y][loc.X]
+	for _, direction := range hlt.CARDINALS {
+		next := m.GetLocation(loc, direction)
+		if c.dist[next.Y][next.X] < here {
+			d = append(d, direction)
+		}
+	}
+	return d
+}
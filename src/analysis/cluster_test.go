@@ -0,0 +1,147 @@
+package analysis
+
+import (
+	"hlt"
+	"testing"
+)
+
+// clusterFixture has rich sites at (0,0) and (5,0), which touch across the
+// wrap, and at (3,1) next to our start, with a middling site at (1,2).
+const clusterFixture = `
+me 1
+owner
+.  .  .  .  .  .
+.  .  .  .  .  .
+.  .  .  1  .  .
+production
+8  1  1  1  1  8
+1  1  1  9  1  1
+1  5  1  1  1  1
+`
+
+func TestFindClusters(t *testing.T) {
+	f := hlt.MustParseFixture(clusterFixture)
+	clusters := FindClusters(f.Map, 10)
+	if len(clusters) != 2 {
+		t.Fatalf("found %d clusters, want 2", len(clusters))
+	}
+	near, wrapped := clusters[0], clusters[1]
+	if len(near.Sites) != 1 || near.Sites[0] != hlt.NewLocation(3, 1) || near.Distance != 1 {
+		t.Errorf("best cluster %v at distance %d, want (3,1) at 1", near.Sites, near.Distance)
+	}
+	if len(wrapped.Sites) != 2 || wrapped.Production != 16 || wrapped.Distance != 3 {
+		t.Errorf("second cluster %v production %d at distance %d, want (0,0) and (5,0), 16 at 3",
+			wrapped.Sites, wrapped.Production, wrapped.Distance)
+	}
+	if near.Value() <= wrapped.Value() {
+		t.Errorf("clusters not sorted by value: %v then %v", near.Value(), wrapped.Value())
+	}
+}
+
+func TestFindClustersThreshold(t *testing.T) {
+	f := hlt.MustParseFixture(clusterFixture)
+	for _, tc := range []struct {
+		percent, clusters, sites int
+	}{
+		// 17 neutral sites: the top 10% stop at the 8s, the top 20% at
+		// the 5.
+		{10, 2, 3},
+		{20, 3, 4},
+		// Out of range percents count as 0 and 100.
+		{-5, 1, 1},
+		{500, 1, 17},
+	} {
+		clusters := FindClusters(f.Map, tc.percent)
+		sites := 0
+		for _, c := range clusters {
+			sites += len(c.Sites)
+		}
+		if len(clusters) != tc.clusters || sites != tc.sites {
+			t.Errorf("percent %d: %d clusters of %d sites, want %d of %d", tc.percent, len(clusters), sites, tc.clusters, tc.sites)
+		}
+	}
+}
+
+// targetFixture has our 5x3 block between rich sites at (1,2) and (9,2).
+const targetFixture = `
+me 1
+owner
+.  .  .  .  .  .  .  .  .  .  .  .  .
+.  .  .  1  1  1  1  1  .  .  .  .  .
+.  .  .  1  1  1  1  1  .  .  .  .  .
+.  .  .  1  1  1  1  1  .  .  .  .  .
+.  .  .  .  .  .  .  .  .  .  .  .  .
+production
+1  1  1  1  1  1  1  1  1  1  1  1  1
+1  1  1  1  1  1  1  1  1  1  1  1  1
+1  9  1  1  1  1  1  1  1  9  1  1  1
+1  1  1  1  1  1  1  1  1  1  1  1  1
+1  1  1  1  1  1  1  1  1  1  1  1  1
+`
+
+func TestAssignTargets(t *testing.T) {
+	f := hlt.MustParseFixture(targetFixture)
+	clusters := FindClusters(f.Map, 0)
+	if len(clusters) != 2 {
+		t.Fatalf("found %d clusters, want 2", len(clusters))
+	}
+	left, right := clusters[0], clusters[1]
+	if left.Sites[0] != hlt.NewLocation(1, 2) {
+		left, right = right, left
+	}
+	targets := AssignTargets(f.Map, clusters, 8)
+	for _, tc := range []struct {
+		x, y int
+		want *Cluster
+		d    []hlt.Direction
+	}{
+		// The border takes the nearer cluster.
+		{3, 1, left, []hlt.Direction{hlt.SOUTH, hlt.WEST}},
+		{7, 3, right, []hlt.Direction{hlt.NORTH, hlt.EAST}},
+		{7, 2, right, []hlt.Direction{hlt.EAST}},
+		// The interior takes the target of its nearest border site.
+		{4, 2, left, []hlt.Direction{hlt.WEST}},
+		{6, 2, right, []hlt.Direction{hlt.EAST}},
+	} {
+		loc := hlt.NewLocation(tc.x, tc.y)
+		if c, ok := targets.Target(loc); !ok || c != tc.want {
+			t.Errorf("(%d,%d) targets %v, want %v", tc.x, tc.y, c, tc.want)
+		}
+		if d := targets.Directions(f.Map, loc); !sameDirections(d, tc.d) {
+			t.Errorf("(%d,%d) directions %v, want %v", tc.x, tc.y, d, tc.d)
+		}
+	}
+	if _, ok := targets.Target(hlt.NewLocation(0, 0)); ok {
+		t.Error("a neutral site has a target")
+	}
+	if d := targets.Directions(f.Map, hlt.NewLocation(0, 0)); d != nil {
+		t.Errorf("a neutral site has directions %v", d)
+	}
+}
+
+func TestAssignTargetsLimit(t *testing.T) {
+	f := hlt.MustParseFixture(targetFixture)
+	clusters := FindClusters(f.Map, 0)
+	one := AssignTargets(f.Map, clusters, 1)
+	if c, ok := one.Target(hlt.NewLocation(7, 2)); !ok || c != clusters[0] {
+		t.Errorf("with one cluster (7,2) targets %v, want %v", c, clusters[0])
+	}
+	for _, max := range []int{0, -1} {
+		none := AssignTargets(f.Map, clusters, max)
+		if len(none.Clusters) != 0 || none.Directions(f.Map, hlt.NewLocation(3, 2)) != nil {
+			t.Errorf("max %d kept %d clusters", max, len(none.Clusters))
+		}
+	}
+}
+
+func sameDirections(a, b []hlt.Direction) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
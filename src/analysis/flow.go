@@ -0,0 +1,58 @@
+package analysis
+
+import "hlt"
+
+// BorderFlow is a flow field over our territory leading every site to the
+// nearest site on our border, moving only through our own land.
+type BorderFlow struct {
+	// dist is the steps to the border from each of our sites, 0 on the
+	// border and -1 off our territory.
+	dist [][]int
+}
+
+func NewBorderFlow(m hlt.GameMap) *BorderFlow {
+	f := &BorderFlow{dist: make([][]int, m.Height)}
+	var queue []hlt.Location
+	for y := range f.dist {
+		f.dist[y] = make([]int, m.Width)
+		for x := range f.dist[y] {
+			f.dist[y][x] = -1
+			loc := hlt.NewLocation(x, y)
+			if m.Contents[y][x].IsMine() && onBorder(m, loc) {
+				f.dist[y][x] = 0
+				queue = append(queue, loc)
+			}
+		}
+	}
+	for len(queue) > 0 {
+		loc := queue[0]
+		queue = queue[1:]
+		for _, d := range hlt.CARDINALS {
+			next := m.GetLocation(loc, d)
+			if m.Contents[next.Y][next.X].IsMine() && f.dist[next.Y][next.X] < 0 {
+				f.dist[next.Y][next.X] = f.dist[loc.Y][loc.X] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+	return f
+}
+
+// Distance is the number of steps from one of our sites to the border, and
+// -1 for sites that are not ours.
+func (f *BorderFlow) Distance(loc hlt.Location) int {
+	return f.dist[loc.Y][loc.X]
+}
+
+// Directions lists the moves that take a piece on one of our sites a step
+// closer to the border.
+func (f *BorderFlow) Directions(m hlt.GameMap, loc hlt.Location) (d []hlt.Direction) {
+	here := f.dist[loc.Y][loc.X]
+	for _, direction := range hlt.CARDINALS {
+		next := m.GetLocation(loc, direction)
+		if there := f.dist[next.Y][next.X]; there >= 0 && there < here {
+			d = append(d, direction)
+		}
+	}
+	return d
+}
@@ -0,0 +1,103 @@
+// Package analysis computes whole-map views of a frame, such as how much
+// neutral land is worth, that decisions can share instead of scanning the
+// map piece by piece.
+package analysis
+
+import (
+	"hlt"
+	"math"
+)
+
+// Heatmap scores every site by the neutral land around it. A neutral site
+// is worth its production per point of strength it costs to take; a site's
+// score adds up the worth of every neutral site within the radius, each
+// weighted by decay to the power of its distance, wrapping around the map
+// edges. Sites we or enemies own are worth nothing themselves but still
+// score by the land around them.
+type Heatmap struct {
+	Width, Height int
+	scores        [][]float64
+	max           float64
+}
+
+// NewHeatmap scores the sites of m. The radius is cut down to fit the map
+// so no site is counted twice through the wrap.
+func NewHeatmap(m hlt.GameMap, radius int, decay float64) *Heatmap {
+	if limit := (min(m.Width, m.Height) - 1) / 2; radius > limit {
+		radius = limit
+	}
+	h := &Heatmap{Width: m.Width, Height: m.Height, scores: make([][]float64, m.Height)}
+	worth := make([][]float64, m.Height)
+	for y := range worth {
+		worth[y] = make([]float64, m.Width)
+		h.scores[y] = make([]float64, m.Width)
+		for x := range worth[y] {
+			worth[y][x] = SiteWorth(m.Contents[y][x])
+		}
+	}
+
+	// Each offset within the radius is weighted once, then every site adds
+	// up its wrapped neighbourhood.
+	type offset struct {
+		dx, dy int
+		weight float64
+	}
+	var offsets []offset
+	for dy := -radius; dy <= radius; dy++ {
+		for dx := -radius; dx <= radius; dx++ {
+			d := abs(dx) + abs(dy)
+			if d <= radius {
+				offsets = append(offsets, offset{dx, dy, math.Pow(decay, float64(d))})
+			}
+		}
+	}
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			score := 0.0
+			for _, o := range offsets {
+				score += o.weight * worth[wrap(y+o.dy, m.Height)][wrap(x+o.dx, m.Width)]
+			}
+			h.scores[y][x] = score
+			if score > h.max {
+				h.max = score
+			}
+		}
+	}
+	return h
+}
+
+// SiteWorth is what a single neutral site is worth: production per point of
+// strength needed to take it.
+func SiteWorth(site hlt.Site) float64 {
+	if !site.IsNeutral() {
+		return 0
+	}
+	return float64(site.Production) / float64(site.Strength+1)
+}
+
+func (h *Heatmap) At(loc hlt.Location) float64 {
+	return h.scores[loc.Y][loc.X]
+}
+
+// Max is the highest score on the map, 0 when no neutral land is left.
+func (h *Heatmap) Max() float64 {
+	return h.max
+}
+
+func wrap(i, n int) int {
+	return ((i % n) + n) % n
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
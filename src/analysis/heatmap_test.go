@@ -0,0 +1,69 @@
+package analysis
+
+import (
+	"hlt"
+	"math"
+	"testing"
+)
+
+// oneNeutral is a width x height map we own but for the neutral site at
+// (x,y), which is worth 2.
+func oneNeutral(width, height, x, y int) hlt.GameMap {
+	m := hlt.NewGameMap(width, height)
+	for row := range m.Contents {
+		for col := range m.Contents[row] {
+			m.Contents[row][col].Owner = 1
+		}
+	}
+	m.Contents[y][x] = hlt.Site{Owner: hlt.Neutral, Strength: 1, Production: 4}
+	return m
+}
+
+func checkHeat(t *testing.T, h *Heatmap, x, y int, want float64) {
+	t.Helper()
+	if got := h.At(hlt.NewLocation(x, y)); math.Abs(got-want) > 1e-9 {
+		t.Errorf("heat at (%d,%d) = %v, want %v", x, y, got, want)
+	}
+}
+
+func TestHeatmapDecay(t *testing.T) {
+	h := NewHeatmap(oneNeutral(9, 9, 4, 4), 3, 0.5)
+	checkHeat(t, h, 4, 4, 2)
+	checkHeat(t, h, 5, 4, 1)
+	checkHeat(t, h, 5, 5, 0.5)
+	checkHeat(t, h, 4, 1, 0.25)
+	checkHeat(t, h, 6, 6, 0)
+	checkHeat(t, h, 0, 0, 0)
+	if h.Max() != 2 {
+		t.Errorf("max %v, want 2", h.Max())
+	}
+}
+
+func TestHeatmapWraps(t *testing.T) {
+	h := NewHeatmap(oneNeutral(7, 7, 0, 0), 2, 0.5)
+	checkHeat(t, h, 6, 0, 1)
+	checkHeat(t, h, 0, 6, 1)
+	checkHeat(t, h, 6, 6, 0.5)
+	checkHeat(t, h, 0, 5, 0.5)
+	checkHeat(t, h, 4, 0, 0)
+}
+
+func TestHeatmapClampsRadius(t *testing.T) {
+	// A radius of 5 on a map 3 high would reach the neutral site again
+	// through the wrap; it is cut to 1.
+	h := NewHeatmap(oneNeutral(9, 3, 0, 0), 5, 1)
+	checkHeat(t, h, 0, 0, 2)
+	checkHeat(t, h, 0, 1, 2)
+	checkHeat(t, h, 0, 2, 2)
+	checkHeat(t, h, 8, 0, 2)
+	checkHeat(t, h, 1, 1, 0)
+	checkHeat(t, h, 2, 0, 0)
+}
+
+func TestHeatmapWithoutNeutrals(t *testing.T) {
+	m := oneNeutral(5, 5, 0, 0)
+	m.Contents[0][0].Owner = 2
+	if h := NewHeatmap(m, 2, 0.5); h.Max() != 0 {
+		t.Errorf("max %v with no neutral land, want 0", h.Max())
+	}
+}
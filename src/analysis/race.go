@@ -0,0 +1,162 @@
+package analysis
+
+import (
+	"container/heap"
+	"hlt"
+	"math"
+)
+
+// Claim says how a neutral site stands in the expansion race from one
+// player's point of view.
+type Claim int
+
+const (
+	// Unreachable sites cannot be reached by the player without going
+	// through enemy territory.
+	Unreachable Claim = iota
+	// Safe sites the player reaches at least the race's margin before
+	// anyone else.
+	Safe
+	// Contested sites are reached by the player and someone else within the
+	// margin of each other.
+	Contested
+	// Lost sites someone else reaches at least the margin earlier.
+	Lost
+)
+
+var claimNames = []string{"unreachable", "safe", "contested", "lost"}
+
+func (c Claim) String() string {
+	if c < 0 || int(c) >= len(claimNames) {
+		return "unknown"
+	}
+	return claimNames[c]
+}
+
+// Race estimates for every neutral site the turn at which each player could
+// capture it. A player expands from all of its sites at once; stepping onto
+// a neutral site takes a turn plus the turns the player's total production
+// needs to pay for the site's strength. Players cannot expand through each
+// other's territory. It is a production-weighted Voronoi diagram of the
+// neutral land.
+type Race struct {
+	Players []hlt.PlayerID
+	// Margin is how many turns apart two players must arrive for the
+	// earlier one to be safe.
+	Margin float64
+	// turns[i][y][x] is the arrival turn of Players[i], +Inf if never.
+	turns [][][]float64
+}
+
+func NewRace(m hlt.GameMap, margin float64) *Race {
+	r := &Race{Players: m.Players(), Margin: margin}
+	for _, p := range r.Players {
+		r.turns = append(r.turns, arrivals(m, p))
+	}
+	return r
+}
+
+func arrivals(m hlt.GameMap, p hlt.PlayerID) [][]float64 {
+	production := 0
+	turns := make([][]float64, m.Height)
+	q := &siteQueue{}
+	for y := range turns {
+		turns[y] = make([]float64, m.Width)
+		for x := range turns[y] {
+			turns[y][x] = math.Inf(1)
+			site := m.Contents[y][x]
+			if site.Owner == p {
+				production += site.Production
+				turns[y][x] = 0
+				heap.Push(q, queued{hlt.NewLocation(x, y), 0})
+			}
+		}
+	}
+	if production < 1 {
+		production = 1
+	}
+	for q.Len() > 0 {
+		next := heap.Pop(q).(queued)
+		if next.turn > turns[next.loc.Y][next.loc.X] {
+			continue
+		}
+		for _, d := range hlt.CARDINALS {
+			loc := m.GetLocation(next.loc, d)
+			site := m.Contents[loc.Y][loc.X]
+			if !site.IsNeutral() {
+				continue
+			}
+			t := next.turn + 1 + float64(site.Strength)/float64(production)
+			if t < turns[loc.Y][loc.X] {
+				turns[loc.Y][loc.X] = t
+				heap.Push(q, queued{loc, t})
+			}
+		}
+	}
+	return turns
+}
+
+// Turn is when player p could capture loc, and false if it never could.
+func (r *Race) Turn(p hlt.PlayerID, loc hlt.Location) (float64, bool) {
+	for i, player := range r.Players {
+		if player == p {
+			t := r.turns[i][loc.Y][loc.X]
+			return t, !math.IsInf(t, 1)
+		}
+	}
+	return 0, false
+}
+
+// Claim labels a site for player p by comparing its arrival with the
+// earliest of the other players.
+func (r *Race) Claim(p hlt.PlayerID, loc hlt.Location) Claim {
+	mine, ok := r.Turn(p, loc)
+	if !ok {
+		return Unreachable
+	}
+	theirs := math.Inf(1)
+	for i, player := range r.Players {
+		if player != p && r.turns[i][loc.Y][loc.X] < theirs {
+			theirs = r.turns[i][loc.Y][loc.X]
+		}
+	}
+	switch {
+	case mine+r.Margin <= theirs:
+		return Safe
+	case theirs+r.Margin <= mine:
+		return Lost
+	}
+	return Contested
+}
+
+// Claims counts the neutral sites of m in each claim for player p.
+func (r *Race) Claims(m hlt.GameMap, p hlt.PlayerID) map[Claim]int {
+	counts := make(map[Claim]int)
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if m.Contents[y][x].IsNeutral() {
+				counts[r.Claim(p, hlt.NewLocation(x, y))]++
+			}
+		}
+	}
+	return counts
+}
+
+type queued struct {
+	loc  hlt.Location
+	turn float64
+}
+
+type siteQueue []queued
+
+func (q siteQueue) Len() int            { return len(q) }
+func (q siteQueue) Less(i, j int) bool  { return q[i].turn < q[j].turn }
+func (q siteQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *siteQueue) Push(x interface{}) { *q = append(*q, x.(queued)) }
+func (q *siteQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	x := old[n-1]
+	*q = old[:n-1]
+	return x
+}
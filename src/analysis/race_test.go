@@ -0,0 +1,77 @@
+package analysis
+
+import (
+	"hlt"
+	"testing"
+)
+
+// raceFixture has players 1 and 2 on a row with player 3 walling off the
+// neutral site at (9,1) and the right of the map. Neutral land is free, so
+// arrival turns are steps.
+const raceFixture = `
+me 1
+owner
+. . . . . . . . . 3 . .
+. 1 . . . 2 . . 3 . 3 .
+. . . . . . . . . 3 . .
+production
+0 0 0 0 0 0 0 0 0 1 0 0
+0 1 0 0 0 1 0 0 1 0 1 0
+0 0 0 0 0 0 0 0 0 1 0 0
+`
+
+func TestRaceClaims(t *testing.T) {
+	f, err := hlt.ParseFixture(raceFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewRace(f.Map, 1.5)
+	for _, tc := range []struct {
+		p    hlt.PlayerID
+		x, y int
+		want Claim
+	}{
+		{1, 2, 1, Safe},
+		{1, 3, 1, Contested},
+		{1, 4, 1, Lost},
+		{2, 4, 1, Safe},
+		// Reachable only through player 3's land.
+		{1, 9, 1, Unreachable},
+		{2, 9, 1, Unreachable},
+		{3, 9, 1, Safe},
+	} {
+		if got := r.Claim(tc.p, hlt.NewLocation(tc.x, tc.y)); got != tc.want {
+			t.Errorf("player %d at (%d,%d): %v, want %v", tc.p, tc.x, tc.y, got, tc.want)
+		}
+	}
+	if turn, ok := r.Turn(1, hlt.NewLocation(3, 1)); !ok || turn != 2 {
+		t.Errorf("player 1 reaches (3,1) on turn %v, %v, want 2", turn, ok)
+	}
+	if _, ok := r.Turn(1, hlt.NewLocation(9, 1)); ok {
+		t.Error("player 1 reaches (9,1)")
+	}
+}
+
+func TestRaceStrengthSlowsArrival(t *testing.T) {
+	f, err := hlt.ParseFixture(`
+me 1
+owner
+1 . . . 2 .
+strength
+0 0 40 0 0 0
+production
+2 0 0 0 40 0
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewRace(f.Map, 1)
+	// (2,0) costs player 1 twenty turns of production and player 2, with
+	// 40 production, one.
+	if got := r.Claim(1, hlt.NewLocation(2, 0)); got != Lost {
+		t.Errorf("player 1 at (2,0): %v, want lost", got)
+	}
+	if turn, _ := r.Turn(1, hlt.NewLocation(2, 0)); turn != 22 {
+		t.Errorf("player 1 reaches (2,0) on turn %v, want 22", turn)
+	}
+}
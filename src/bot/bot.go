@@ -0,0 +1,373 @@
+// Package bot holds the bots' state and decision logic, with the rules that
+// differ between bots supplied by a Strategy.
+package bot
+
+import (
+	"analysis"
+	"hlt"
+	"log"
+	"math/rand"
+	"runtime"
+	"time"
+)
+
+type moveMap map[hlt.Location]hlt.Direction
+
+// Bot decides the moves of one player. It keeps everything it needs between
+// turns itself, so several bots can play in one process.
+type Bot struct {
+	info         hlt.GameInfo
+	gameMap      hlt.GameMap
+	lastMoves    moveMap
+	currentMoves moveMap
+	rng          *rand.Rand
+	strategy     Strategy
+	params       Params
+	workers      int
+	turn         int
+	phase        Phase
+	initBudget   time.Duration
+	initDeadline time.Time
+	// frame holds the shared analyses of the current frame.
+	frame *frame
+}
+
+// New creates a bot playing the strategy whose random choices are drawn from
+// the given seed.
+func New(strategy Strategy, seed int64) *Bot {
+	return &Bot{
+		strategy:     strategy,
+		params:       DefaultParams(),
+		lastMoves:    make(moveMap),
+		currentMoves: make(moveMap),
+		rng:          rand.New(rand.NewSource(seed)),
+		workers:      runtime.GOMAXPROCS(0),
+	}
+}
+
+func (b *Bot) SetParams(p Params) {
+	b.params = p
+}
+
+func (b *Bot) Params() Params {
+	return b.params
+}
+
+// SetWorkers sets how many goroutines decide moves in parallel each turn.
+// The moves do not depend on it.
+func (b *Bot) SetWorkers(n int) {
+	if n < 1 {
+		n = 1
+	}
+	b.workers = n
+}
+
+// SetInitBudget bounds the time strategies may spend preparing in Init.
+// Zero, the default, leaves them unbounded.
+func (b *Bot) SetInitBudget(d time.Duration) {
+	b.initBudget = d
+}
+
+// InitDeadline is when the current Init has to finish, if it has a budget.
+// Strategies doing heavy work in InitGame should check it and settle for the
+// best result so far once it has passed.
+func (b *Bot) InitDeadline() (time.Time, bool) {
+	return b.initDeadline, !b.initDeadline.IsZero()
+}
+
+// Init starts a new game and forgets any moves from a previous one, then
+// lets the strategy prepare within the init budget.
+func (b *Bot) Init(info hlt.GameInfo) {
+	start := time.Now()
+	b.initDeadline = time.Time{}
+	if b.initBudget > 0 {
+		b.initDeadline = start.Add(b.initBudget)
+	}
+	b.info = info
+	b.lastMoves = make(moveMap)
+	b.currentMoves = make(moveMap)
+	b.turn = 0
+	b.phase = Opening
+	if hook, ok := b.strategy.(InitHook); ok {
+		hook.InitGame(b)
+		log.Printf("Init took %v of %v", time.Since(start), b.initBudget)
+	}
+}
+
+// Decide returns the direction the bot would choose for one site of the map,
+// given the moves it made on the previous turn, without recording it.
+func (b *Bot) Decide(m hlt.GameMap, previous hlt.MoveSet, loc hlt.Location) hlt.Direction {
+	b.gameMap = m
+	b.lastMoves = make(moveMap)
+	for _, mv := range previous {
+		b.lastMoves[mv.Location] = mv.Direction
+	}
+	b.analyse()
+	if hook, ok := b.strategy.(TurnHook); ok {
+		hook.BeginTurn(b)
+	}
+	return b.strategy.Direction(b, loc)
+}
+
+// analyse prepares the views of the current frame that decisions share.
+func (b *Bot) analyse() {
+	b.updatePhase()
+	b.frame = &frame{m: b.gameMap, params: b.params}
+}
+
+// Heatmap returns the valuation of the current frame's neutral land.
+func (b *Bot) Heatmap() *analysis.Heatmap {
+	return b.frame.getHeatmap()
+}
+
+// Targets returns the production clusters of the current frame and the
+// parts of our border assigned to them.
+func (b *Bot) Targets() *analysis.Targets {
+	return b.frame.getTargets()
+}
+
+// Race returns when each player could reach the current frame's neutral
+// land.
+func (b *Bot) Race() *analysis.Race {
+	return b.frame.getRace()
+}
+
+// BorderFlow returns the paths from our interior to the current frame's
+// border.
+func (b *Bot) BorderFlow() *analysis.BorderFlow {
+	return b.frame.getBorderFlow()
+}
+
+func (b *Bot) hasOnlyFriendlyNeighbours(l hlt.Location) bool {
+	for _, d := range hlt.CARDINALS {
+		if !b.gameMap.GetSite(l, d).IsMine() {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *Bot) isNotMe(loc hlt.Location) bool {
+	return !b.gameMap.GetSite(loc, hlt.STILL).IsMine()
+}
+
+func (b *Bot) pickRandomNonReversedDirection(loc hlt.Location, dl []hlt.Direction, canEnter enterRule) hlt.Direction {
+	dl = b.pruneDirections(loc, dl, canEnter)
+	return dl[b.rng.Intn(len(dl))]
+}
+
+func (b *Bot) hasEnemyNeighbour(loc hlt.Location) bool {
+	for _, direction := range hlt.CARDINALS {
+		site := b.gameMap.GetSite(loc, direction)
+		if !site.IsMine() {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Bot) getOpponentDirections(loc hlt.Location) (d []hlt.Direction) {
+	strongest := 0
+	for _, direction := range hlt.CARDINALS {
+		site := b.gameMap.GetSite(loc, direction)
+
+		if site.Strength >= strongest && !site.IsMine() && !site.IsNeutral() {
+			if site.Strength > strongest {
+				strongest = site.Strength
+				d = make([]hlt.Direction, 0)
+			}
+			d = append(d, direction)
+		}
+	}
+	return d
+}
+
+func (b *Bot) getOpponentOrWeakNeutralDirections(loc hlt.Location) (d []hlt.Direction) {
+	for _, direction := range hlt.CARDINALS {
+		site := b.gameMap.GetSite(loc, direction)
+		if !site.IsMine() && (!site.IsNeutral() || site.Strength < b.params.WeakNeutralStrength) {
+			d = append(d, direction)
+		}
+	}
+	return d
+}
+
+func (b *Bot) getDefeatableNeutralDirections(loc hlt.Location) (d []hlt.Direction) {
+	for _, direction := range hlt.CARDINALS {
+		site := b.gameMap.GetSite(loc, direction)
+		if !site.IsMine() && !site.IsNeutral() {
+			d = append(d, direction)
+		}
+	}
+	return d
+}
+
+func (b *Bot) getStrength(loc hlt.Location) int {
+	return b.gameMap.GetSite(loc, hlt.STILL).Strength
+}
+
+func (b *Bot) getMostValuableNeutralDirections(fromLocation hlt.Location) []hlt.Direction {
+	highestValue := -1000
+	highValueDirections := make([]hlt.Direction, 0)
+	var currentLocation hlt.Location
+	for _, direction := range hlt.CARDINALS {
+		currentLocation = fromLocation
+		log.Printf("Looking towards %v", direction)
+		for distance := 1; distance < b.gameMap.Width/2+1; distance++ {
+			currentLocation = b.gameMap.GetLocation(currentLocation, direction)
+			site := b.gameMap.GetSite(currentLocation, hlt.STILL)
+
+			if site.IsNeutral() && (site.Production > 0) {
+				locationValue := b.getSiteValue(b.gameMap.GetLocation(currentLocation, direction), 0) - b.params.NeutralDistancePenalty*distance*distance
+				if highestValue < locationValue {
+					highestValue = locationValue
+					highValueDirections = make([]hlt.Direction, 0)
+				}
+				if highestValue == locationValue {
+					highValueDirections = append(highValueDirections, direction)
+				}
+				break
+			}
+			if b.isNotMe(currentLocation) {
+				break
+			}
+		}
+	}
+	log.Printf("Most valuable opponent is towards %v", highValueDirections)
+	return highValueDirections
+}
+
+// getClusterDirections heads for the production cluster assigned to the
+// piece's part of the border.
+func (b *Bot) getClusterDirections(loc hlt.Location) []hlt.Direction {
+	return b.Targets().Directions(b.gameMap, loc)
+}
+
+func (b *Bot) getSiteValue(l hlt.Location, recurseDepth int) int {
+	value := 0
+	for _, d := range hlt.CARDINALS {
+		s := b.gameMap.GetSite(l, d)
+		if !s.IsMine() {
+			value += s.Production*s.Production - s.Strength
+		}
+		if recurseDepth > 0 {
+			value += b.getSiteValue(b.gameMap.GetLocation(l, d), recurseDepth-1)
+		}
+	}
+	return value
+}
+
+func (b *Bot) getClosestEnemy(fromLocation hlt.Location) []hlt.Direction {
+	closest := 255
+	closestDirections := make([]hlt.Direction, 0)
+	var currentLocation hlt.Location
+	for _, direction := range hlt.CARDINALS {
+		currentLocation = fromLocation
+		log.Printf("Looking towards %v", direction)
+		for distance := 0; distance < b.gameMap.Height/2+1; distance++ {
+			currentLocation = b.gameMap.GetLocation(currentLocation, direction)
+			site := b.gameMap.GetSite(currentLocation, hlt.STILL)
+
+			if distance > 0 && !site.IsMine() && !site.IsNeutral() {
+				if distance < closest {
+					closest = distance
+					closestDirections = make([]hlt.Direction, 0)
+				}
+				if distance == closest {
+					closestDirections = append(closestDirections, direction)
+				}
+				break
+			} else if !site.IsMine() && site.Strength > b.params.EnemyScanMaxStrength {
+				break
+			}
+		}
+	}
+	return closestDirections
+}
+
+func (b *Bot) getWeakestDefeatableNeighbour(fromLocation hlt.Location) (d []hlt.Direction) {
+	weakest := 255
+	for _, direction := range hlt.CARDINALS {
+		site := b.gameMap.GetSite(fromLocation, direction)
+		if site.Strength <= weakest &&
+			!site.IsMine() &&
+			b.shouldAttack(fromLocation, direction) {
+			if site.Strength < weakest {
+				weakest = site.Strength
+				d = make([]hlt.Direction, 0)
+			}
+			d = append(d, direction)
+		}
+	}
+	return
+}
+func (b *Bot) getHighestValueNeutralNeighbours(loc hlt.Location) (d []hlt.Direction) {
+	mostValue := -10000
+	for _, direction := range hlt.CARDINALS {
+		l := b.gameMap.GetLocation(loc, direction)
+		site := b.gameMap.GetSite(loc, direction)
+		siteValue := b.getSiteValue(b.gameMap.GetLocation(l, direction), 0)
+		if site.IsNeutral() && siteValue >= mostValue && b.shouldAttack(loc, direction) {
+			if siteValue > mostValue {
+				d = make([]hlt.Direction, 0)
+				mostValue = siteValue
+			}
+			d = append(d, direction)
+		}
+	}
+	return d
+}
+
+// getHottestNeutralNeighbours picks the neutral neighbours we can take
+// whose heatmap score is highest.
+func (b *Bot) getHottestNeutralNeighbours(loc hlt.Location) (d []hlt.Direction) {
+	best := -1.0
+	for _, direction := range hlt.CARDINALS {
+		if !b.gameMap.GetSite(loc, direction).IsNeutral() || !b.shouldAttack(loc, direction) {
+			continue
+		}
+		score := b.Heatmap().At(b.gameMap.GetLocation(loc, direction))
+		if score > best {
+			best = score
+			d = nil
+		}
+		if score == best {
+			d = append(d, direction)
+		}
+	}
+	return d
+}
+
+func (b *Bot) shouldAttack(l hlt.Location, d hlt.Direction) bool {
+	return b.getStrength(l) > b.getStrength(b.gameMap.GetLocation(l, d))
+}
+
+func opposite(d hlt.Direction) hlt.Direction {
+	if d == hlt.STILL {
+		return hlt.STILL
+	}
+	return hlt.CARDINALS[(d+1)%4]
+}
+
+// enterRule reports whether a piece at loc may move in direction d.
+type enterRule func(b *Bot, loc hlt.Location, d hlt.Direction) bool
+
+func (b *Bot) pruneDirections(loc hlt.Location, directions []hlt.Direction, canEnter enterRule) []hlt.Direction {
+	newDirections := make([]hlt.Direction, 0)
+	for _, d := range directions {
+
+		destinationLocation := b.gameMap.GetLocation(loc, d)
+		if lm, ok := b.lastMoves[destinationLocation]; ok && lm == opposite(d) {
+
+		} else {
+			if canEnter(b, loc, d) {
+				newDirections = append(newDirections, d)
+			}
+		}
+
+	}
+	if len(newDirections) == 0 {
+		newDirections = append(newDirections, hlt.STILL)
+	}
+	return newDirections
+}
@@ -0,0 +1,54 @@
+package bot
+
+import (
+	"analysis"
+	"hlt"
+	"sync"
+)
+
+// frame holds the analyses of one frame that decisions share. Each is
+// computed the first time a decision asks for it and only once, however
+// many workers ask at the same time, so strategies that never use one pay
+// nothing for it.
+type frame struct {
+	m      hlt.GameMap
+	params Params
+
+	heatmapOnce sync.Once
+	heatmap     *analysis.Heatmap
+	targetsOnce sync.Once
+	targets     *analysis.Targets
+	raceOnce    sync.Once
+	race        *analysis.Race
+	flowOnce    sync.Once
+	flow        *analysis.BorderFlow
+}
+
+func (f *frame) getHeatmap() *analysis.Heatmap {
+	f.heatmapOnce.Do(func() {
+		f.heatmap = analysis.NewHeatmap(f.m, f.params.HeatRadius, f.params.HeatDecay)
+	})
+	return f.heatmap
+}
+
+func (f *frame) getTargets() *analysis.Targets {
+	f.targetsOnce.Do(func() {
+		clusters := analysis.FindClusters(f.m, f.params.ClusterPercent)
+		f.targets = analysis.AssignTargets(f.m, clusters, f.params.ClusterTargets)
+	})
+	return f.targets
+}
+
+func (f *frame) getRace() *analysis.Race {
+	f.raceOnce.Do(func() {
+		f.race = analysis.NewRace(f.m, f.params.RaceMargin)
+	})
+	return f.race
+}
+
+func (f *frame) getBorderFlow() *analysis.BorderFlow {
+	f.flowOnce.Do(func() {
+		f.flow = analysis.NewBorderFlow(f.m)
+	})
+	return f.flow
+}
@@ -0,0 +1,48 @@
+package bot
+
+import (
+	"bytes"
+	"flag"
+	"golden"
+	"hlt"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "Rewrite the golden files instead of comparing against them")
+
+// goldenSeed is the seed every golden bot draws its random choices from, so
+// the golden files only change when the bot's decisions do.
+const goldenSeed = 1
+
+// goldenTurn plays a fresh mybot drawing from seed on a frame.
+func goldenTurn(seed int64) golden.Turn {
+	return func(f hlt.Fixture) hlt.MoveSet {
+		b := New(mybot(), seed)
+		b.Init(f.Info())
+		return b.Turn(f.Map)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	var out bytes.Buffer
+	failed, err := golden.Check("../../golden", *update, goldenTurn(goldenSeed), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failed > 0 {
+		t.Errorf("%d frames changed, rerun with -update if that is intended\n%s", failed, out.String())
+	}
+}
+
+// TestGoldenSeed checks that the corpus reaches random choices, so the
+// golden files do depend on the seed they were recorded with.
+func TestGoldenSeed(t *testing.T) {
+	var out bytes.Buffer
+	failed, err := golden.Check("../../golden", false, goldenTurn(goldenSeed+1), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failed == 0 {
+		t.Error("every frame plays the same with another seed")
+	}
+}
@@ -0,0 +1,273 @@
+package bot
+
+import (
+	"hlt"
+	"log"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// planState is one node of the opening search: the sites taken so far, in
+// order, and the strength and production they leave the bot with.
+type planState struct {
+	order []hlt.Location
+	owned map[hlt.Location]bool
+	// key identifies the set of owned sites whatever the capture order.
+	key uint64
+	// strength is the bot's total strength, production its production per
+	// turn and produced what it has produced up to turn.
+	strength, production, produced, turn int
+}
+
+// score is the production the bot will have made by turn n if it stops
+// capturing now.
+func (s *planState) score(n int) int {
+	return s.produced + s.production*(n-s.turn)
+}
+
+// PlanOpening searches for the order in which to capture the neutral sites
+// within radius of the player's start that maximises production by the
+// given turn. It is a beam search over capture orders on a simplified game
+// where the bot's strength is one pool that pays for a capture as soon as
+// it exceeds the site's strength, each capture takes a turn and travel is
+// free. beam states survive each step.
+func PlanOpening(m hlt.GameMap, me hlt.PlayerID, turns, radius, beam int) []hlt.Location {
+	return planOpening(m, me, turns, radius, beam, time.Time{}).order
+}
+
+// planOpening is PlanOpening returning the final search state. The search
+// stops early with the best state of the finished steps once a non-zero
+// deadline passes, checked at every state since a wide beam makes one step
+// slow.
+func planOpening(m hlt.GameMap, me hlt.PlayerID, turns, radius, beam int, deadline time.Time) *planState {
+	var home hlt.Location
+	ok := false
+	root := &planState{owned: make(map[hlt.Location]bool)}
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			site := m.Contents[y][x]
+			if site.Owner != me {
+				continue
+			}
+			loc := hlt.NewLocation(x, y)
+			home, ok = loc, true
+			root.owned[loc] = true
+			root.strength += site.Strength
+			root.production += site.Production
+		}
+	}
+	if !ok || beam < 1 {
+		return root
+	}
+
+	// Zobrist keys let states that own the same sites be merged.
+	rng := rand.New(rand.NewSource(1))
+	keys := make(map[hlt.Location]uint64)
+	var candidates []hlt.Location
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			loc := hlt.NewLocation(x, y)
+			site := m.Contents[y][x]
+			if site.IsNeutral() && site.Production > 0 && m.GetDistance(home, loc) <= radius {
+				keys[loc] = rng.Uint64()
+				candidates = append(candidates, loc)
+			}
+		}
+	}
+
+	best := root
+	states := []*planState{root}
+	for len(states) > 0 {
+		children := make(map[uint64]*planState)
+		for _, s := range states {
+			if !deadline.IsZero() && time.Now().After(deadline) {
+				return best
+			}
+			for _, c := range candidates {
+				if s.owned[c] || !bordersOwned(m, s.owned, c) {
+					continue
+				}
+				child := s.capture(c, m.Contents[c.Y][c.X], keys[c])
+				if child == nil || child.turn > turns {
+					continue
+				}
+				if other, ok := children[child.key]; !ok || child.score(turns) > other.score(turns) {
+					children[child.key] = child
+				}
+			}
+		}
+		states = states[:0]
+		for _, child := range children {
+			states = append(states, child)
+		}
+		sort.Slice(states, func(i, j int) bool {
+			if states[i].score(turns) != states[j].score(turns) {
+				return states[i].score(turns) > states[j].score(turns)
+			}
+			return states[i].key < states[j].key
+		})
+		if len(states) > beam {
+			states = states[:beam]
+		}
+		if len(states) > 0 && states[0].score(turns) > best.score(turns) {
+			best = states[0]
+		}
+	}
+	return best
+}
+
+// capture returns the state after taking site at loc, or nil if the bot
+// lacks the strength and has no production to build it up.
+func (s *planState) capture(loc hlt.Location, site hlt.Site, key uint64) *planState {
+	need := site.Strength + 1 - s.strength
+	if need > 0 && s.production == 0 {
+		return nil
+	}
+	child := &planState{
+		order:      append(append([]hlt.Location(nil), s.order...), loc),
+		owned:      make(map[hlt.Location]bool, len(s.owned)+1),
+		key:        s.key ^ key,
+		strength:   s.strength,
+		production: s.production,
+		produced:   s.produced,
+		turn:       s.turn,
+	}
+	for l := range s.owned {
+		child.owned[l] = true
+	}
+	child.owned[loc] = true
+	wait := 0
+	if need > 0 {
+		wait = (need + s.production - 1) / s.production
+	}
+	child.turn += wait + 1
+	child.produced += s.production * (wait + 1)
+	child.strength += s.production*wait - site.Strength
+	child.production += site.Production
+	return child
+}
+
+func bordersOwned(m hlt.GameMap, owned map[hlt.Location]bool, loc hlt.Location) bool {
+	for _, d := range hlt.CARDINALS {
+		if owned[m.GetLocation(loc, d)] {
+			return true
+		}
+	}
+	return false
+}
+
+// maxOpeningBeam bounds the widening in InitGame; wider searches stop
+// finding better plans long before.
+const maxOpeningBeam = 4096
+
+// openingPlanner follows the plan made by PlanOpening when the game starts,
+// one capture at a time, and hands over to fallback once the plan is done,
+// has gone stale or the opening turns are over.
+type openingPlanner struct {
+	fallback Strategy
+	plan     []hlt.Location
+	next     int
+	// moves are this turn's planned moves, nil when fallback decides.
+	moves moveMap
+}
+
+// InitGame plans the opening, stopping every search at the init deadline.
+// With an init budget it keeps doubling the beam while the next search is
+// expected to finish in time, so the plan improves on a bigger budget.
+func (o *openingPlanner) InitGame(b *Bot) {
+	m, me, p := b.info.InitialMap(), b.info.PlayerTag(), b.params
+	deadline, bounded := b.InitDeadline()
+	start := time.Now()
+	best := planOpening(m, me, p.OpeningTurns, p.OpeningRadius, p.OpeningBeam, deadline)
+	took, beam, bestBeam := time.Since(start), p.OpeningBeam, p.OpeningBeam
+	if bounded && beam > 0 {
+		// A search twice as wide takes about twice as long as the last.
+		for beam < maxOpeningBeam && time.Now().Add(2*took).Before(deadline) {
+			beam *= 2
+			start = time.Now()
+			s := planOpening(m, me, p.OpeningTurns, p.OpeningRadius, beam, deadline)
+			took = time.Since(start)
+			if time.Now().After(deadline) {
+				break
+			}
+			if s.score(p.OpeningTurns) > best.score(p.OpeningTurns) {
+				best, bestBeam = s, beam
+			}
+		}
+	}
+	o.plan = best.order
+	o.next = 0
+	o.moves = nil
+	log.Printf("Opening plan with beam %d, production %d by turn %d: %v", bestBeam, best.score(p.OpeningTurns), p.OpeningTurns, o.plan)
+	if hook, ok := o.fallback.(InitHook); ok {
+		hook.InitGame(b)
+	}
+}
+
+func (o *openingPlanner) BeginTurn(b *Bot) {
+	o.moves = nil
+	for o.next < len(o.plan) && b.gameMap.GetSite(o.plan[o.next], hlt.STILL).IsMine() {
+		o.next++
+	}
+	if o.next < len(o.plan) && !b.gameMap.GetSite(o.plan[o.next], hlt.STILL).IsNeutral() {
+		log.Printf("Opening target %v taken by an enemy, abandoning plan", o.plan[o.next])
+		o.next = len(o.plan)
+	}
+	if o.next >= len(o.plan) || b.turn > b.params.OpeningTurns {
+		if hook, ok := o.fallback.(TurnHook); ok {
+			hook.BeginTurn(b)
+		}
+		return
+	}
+	o.moves = o.gather(b, o.plan[o.next])
+}
+
+// gather moves pieces towards target through our own territory. The pieces
+// next to it attack together once they are strong enough between them; the
+// others move one step closer once they have held their site for a while.
+func (o *openingPlanner) gather(b *Bot, target hlt.Location) moveMap {
+	m := b.gameMap
+	moves := make(moveMap)
+	dist := map[hlt.Location]int{target: 0}
+	queue := []hlt.Location{target}
+	for len(queue) > 0 {
+		loc := queue[0]
+		queue = queue[1:]
+		for _, d := range hlt.CARDINALS {
+			next := m.GetLocation(loc, d)
+			if _, seen := dist[next]; seen || !m.GetSite(next, hlt.STILL).IsMine() {
+				continue
+			}
+			dist[next] = dist[loc] + 1
+			queue = append(queue, next)
+			// Pieces step back along the path they were found by.
+			moves[next] = opposite(d)
+		}
+	}
+
+	attack := 0
+	for _, d := range hlt.CARDINALS {
+		if loc := m.GetLocation(target, d); dist[loc] == 1 {
+			attack += b.getStrength(loc)
+		}
+	}
+	ready := attack > b.getStrength(target)
+	for loc := range moves {
+		site := m.GetSite(loc, hlt.STILL)
+		switch {
+		case dist[loc] == 1 && ready:
+		case dist[loc] > 1 && site.Strength > site.Production*b.params.HoldProductionMultiple:
+		default:
+			moves[loc] = hlt.STILL
+		}
+	}
+	return moves
+}
+
+func (o *openingPlanner) Direction(b *Bot, loc hlt.Location) hlt.Direction {
+	if o.moves == nil {
+		return o.fallback.Direction(b, loc)
+	}
+	return o.moves[loc]
+}
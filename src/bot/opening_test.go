@@ -0,0 +1,118 @@
+package bot
+
+import (
+	"hlt"
+	"testing"
+	"time"
+)
+
+func TestPlanOpeningWithoutProduction(t *testing.T) {
+	f, err := hlt.ParseFixture(`
+me 1
+owner
+.  .  .
+.  1  .
+.  .  .
+strength
+50 50 50
+50 10 50
+50 50 50
+production
+2  2  2
+2  0  2
+2  2  2
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan := PlanOpening(f.Map, f.Me, 40, 3, 16); len(plan) != 0 {
+		t.Errorf("planned %v without the strength or production to capture", plan)
+	}
+}
+
+func TestPlanOpeningTakesRichSitesFirst(t *testing.T) {
+	f, err := hlt.ParseFixture(`
+me 1
+owner
+.  .  .  .  .
+.  .  1  .  .
+.  .  .  .  .
+strength
+ 9  9  9  9  9
+ 9  9 20  9  9
+ 9  9  9  9  9
+production
+ 1  1  1  1  1
+ 1  1  2  1  5
+ 1  1  1  1  1
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := PlanOpening(f.Map, f.Me, 10, 3, 16)
+	want := []hlt.Location{hlt.NewLocation(3, 1), hlt.NewLocation(4, 1)}
+	if len(plan) < 2 || plan[0] != want[0] || plan[1] != want[1] {
+		t.Errorf("plan %v, want it to start with %v", plan, want)
+	}
+}
+
+func TestPlanOpeningAcrossVerticalWrap(t *testing.T) {
+	// The map is wider than it is high, so the rich site one step north of
+	// the start across the wrap is measured against the height.
+	f, err := hlt.ParseFixture(`
+me 1
+owner
+.  .  .  .  1  .  .  .  .  .
+.  .  .  .  .  .  .  .  .  .
+.  .  .  .  .  .  .  .  .  .
+.  .  .  .  .  .  .  .  .  .
+strength
+1  1  1  1 20  1  1  1  1  1
+1  1  1  1  1  1  1  1  1  1
+1  1  1  1  1  1  1  1  1  1
+1  1  1  1  1  1  1  1  1  1
+production
+0  0  0  0  1  0  0  0  0  0
+0  0  0  0  0  0  0  0  0  0
+0  0  0  0  0  0  0  0  0  0
+0  0  0  0  9  0  0  0  0  0
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := PlanOpening(f.Map, f.Me, 10, 1, 16)
+	if want := hlt.NewLocation(4, 3); len(plan) != 1 || plan[0] != want {
+		t.Errorf("plan %v, want [%v]", plan, want)
+	}
+}
+
+func TestPlanOpeningStopsAtDeadline(t *testing.T) {
+	f := benchMap(50, 1, 3)
+	start := time.Now()
+	s := planOpening(f.Map, f.Me, 200, 25, maxOpeningBeam, start.Add(100*time.Millisecond))
+	if took := time.Since(start); took > 125*time.Millisecond {
+		t.Errorf("search took %v with a 100ms deadline", took)
+	}
+	if s == nil {
+		t.Error("no plan at the deadline")
+	}
+}
+
+// The first search uses the configured beam, which may alone be too wide
+// for the budget.
+func TestPlannerInitKeepsBudget(t *testing.T) {
+	strategy, err := NewStrategy("planner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := New(strategy, 1)
+	p := DefaultParams()
+	p.OpeningTurns, p.OpeningRadius, p.OpeningBeam = 200, 25, 512
+	b.SetParams(p)
+	b.SetInitBudget(100 * time.Millisecond)
+	start := time.Now()
+	b.Init(benchMap(50, 1, 3).Info())
+	if took := time.Since(start); took > 150*time.Millisecond {
+		t.Errorf("Init took %v with a 100ms budget", took)
+	}
+}
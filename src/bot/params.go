@@ -0,0 +1,172 @@
+package bot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Params are the tunable thresholds of the strategies. The JSON names are
+// also the keys for -param key=value.
+type Params struct {
+	// A piece roams once its strength exceeds this many turns of its own
+	// production.
+	HoldProductionMultiple int `json:"hold_production_multiple"`
+	// Once the bot held more than RoamMinTerritory sites last turn, any
+	// piece stronger than RoamMinStrength roams as well.
+	RoamMinTerritory int `json:"roam_min_territory"`
+	RoamMinStrength  int `json:"roam_min_strength"`
+	// The scan for enemies stops at a neutral site stronger than this.
+	EnemyScanMaxStrength int `json:"enemy_scan_max_strength"`
+	// A neutral target's value drops by this times its distance squared.
+	NeutralDistancePenalty int `json:"neutral_distance_penalty"`
+	// The random strategy attacks neutral neighbours weaker than this.
+	WeakNeutralStrength int `json:"weak_neutral_strength"`
+	// Contact begins when an enemy is this many steps from our territory.
+	ContactDistance int `json:"contact_distance"`
+	// War begins when this percentage of our border touches enemies.
+	WarFrontPercent int `json:"war_front_percent"`
+	// The endgame is this last percentage of the turn limit, or begins once
+	// less than this percentage of the map is neutral.
+	EndgamePercent int `json:"endgame_percent"`
+	// The opening planner plans captures up to OpeningTurns among the
+	// neutral sites within OpeningRadius of the start, keeping OpeningBeam
+	// candidate orders at each step of its search.
+	OpeningTurns  int `json:"opening_turns"`
+	OpeningRadius int `json:"opening_radius"`
+	OpeningBeam   int `json:"opening_beam"`
+	// The heatmap adds up the neutral land within HeatRadius of a site,
+	// weighting each site by HeatDecay to the power of its distance.
+	HeatRadius int     `json:"heat_radius"`
+	HeatDecay  float64 `json:"heat_decay"`
+	// Clusters are made of the neutral sites in the top ClusterPercent of
+	// production; the best ClusterTargets of them are assigned to the
+	// border.
+	ClusterPercent int `json:"cluster_percent"`
+	ClusterTargets int `json:"cluster_targets"`
+	// Neutral land is contested when another player could take it within
+	// RaceMargin turns of us.
+	RaceMargin float64 `json:"race_margin"`
+	// Interior pieces reinforce the border once their strength is this many
+	// times the production their trip there gives up.
+	ReinforceMultiple int `json:"reinforce_multiple"`
+}
+
+func DefaultParams() Params {
+	return Params{
+		HoldProductionMultiple: 4,
+		RoamMinTerritory:       15,
+		RoamMinStrength:        30,
+		EnemyScanMaxStrength:   5,
+		NeutralDistancePenalty: 1,
+		WeakNeutralStrength:    3,
+		ContactDistance:        3,
+		WarFrontPercent:        25,
+		EndgamePercent:         10,
+		OpeningTurns:           40,
+		OpeningRadius:          6,
+		OpeningBeam:            16,
+		HeatRadius:             3,
+		HeatDecay:              0.5,
+		ClusterPercent:         20,
+		ClusterTargets:         8,
+		RaceMargin:             3,
+		ReinforceMultiple:      5,
+	}
+}
+
+// LoadParams reads a JSON object of parameters from path. Parameters missing
+// from the file keep their default values.
+func LoadParams(path string) (Params, error) {
+	p := DefaultParams()
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return p, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return p, fmt.Errorf("%s: %v", path, err)
+	}
+	return p, nil
+}
+
+// Set changes the parameter with the given JSON name.
+func (p *Params) Set(key, value string) error {
+	v := reflect.ValueOf(p).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if paramKey(v.Type().Field(i)) != key {
+			continue
+		}
+		f := v.Field(i)
+		switch f.Kind() {
+		case reflect.Int:
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("param %s: %v", key, err)
+			}
+			f.SetInt(int64(n))
+		case reflect.Float64:
+			x, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("param %s: %v", key, err)
+			}
+			f.SetFloat(x)
+		default:
+			return fmt.Errorf("param %s: unsupported type %v", key, f.Type())
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown param %q, have %v", key, ParamKeys())
+}
+
+// ParamKeys lists the names Set accepts.
+func ParamKeys() []string {
+	t := reflect.TypeOf(Params{})
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		keys = append(keys, paramKey(t.Field(i)))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func paramKey(f reflect.StructField) string {
+	return strings.Split(f.Tag.Get("json"), ",")[0]
+}
+
+func (p Params) String() string {
+	data, _ := json.Marshal(p)
+	return string(data)
+}
+
+// ParamFlags collects repeated -param key=value flags, to be applied on top
+// of defaults or a params file once all flags are parsed.
+type ParamFlags []string
+
+func (f *ParamFlags) String() string {
+	return strings.Join(*f, " ")
+}
+
+func (f *ParamFlags) Set(kv string) error {
+	if !strings.Contains(kv, "=") {
+		return fmt.Errorf("want key=value, got %q", kv)
+	}
+	*f = append(*f, kv)
+	return nil
+}
+
+func (f ParamFlags) Apply(p *Params) error {
+	for _, kv := range f {
+		i := strings.Index(kv, "=")
+		if err := p.Set(kv[:i], kv[i+1:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package bot
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParamsSet(t *testing.T) {
+	p := DefaultParams()
+	if err := p.Set("opening_beam", "64"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Set("heat_decay", "0.25"); err != nil {
+		t.Fatal(err)
+	}
+	if p.OpeningBeam != 64 || p.HeatDecay != 0.25 {
+		t.Errorf("Set gave opening_beam %d heat_decay %v", p.OpeningBeam, p.HeatDecay)
+	}
+	for _, kv := range [][2]string{
+		{"opening_beam", "1.5"},
+		{"opening_beam", ""},
+		{"heat_decay", "half"},
+		{"no_such_param", "1"},
+	} {
+		if err := p.Set(kv[0], kv[1]); err == nil {
+			t.Errorf("Set(%q, %q) succeeded", kv[0], kv[1])
+		}
+	}
+	if p.OpeningBeam != 64 || p.HeatDecay != 0.25 {
+		t.Errorf("a failed Set changed the params to %v", p)
+	}
+}
+
+func TestParamFlags(t *testing.T) {
+	var f ParamFlags
+	for _, kv := range []string{"opening_beam", ""} {
+		if err := f.Set(kv); err == nil {
+			t.Errorf("Set(%q) accepted a flag without =", kv)
+		}
+	}
+	for _, kv := range []string{"opening_beam=8", "heat_decay=0.75", "opening_beam=32"} {
+		if err := f.Set(kv); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p := DefaultParams()
+	if err := f.Apply(&p); err != nil {
+		t.Fatal(err)
+	}
+	want := DefaultParams()
+	want.OpeningBeam, want.HeatDecay = 32, 0.75
+	if p != want {
+		t.Errorf("Apply gave %v, want %v", p, want)
+	}
+	for _, kv := range []string{"=1", "no_such_param=1", "opening_beam=x"} {
+		p := DefaultParams()
+		if err := (ParamFlags{kv}).Apply(&p); err == nil {
+			t.Errorf("Apply(%q) succeeded", kv)
+		}
+	}
+}
+
+func TestLoadParams(t *testing.T) {
+	dir, err := ioutil.TempDir("", "params")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	p, err := LoadParams(write("partial.json", `{"opening_beam": 64, "heat_decay": 0.25}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := DefaultParams()
+	want.OpeningBeam, want.HeatDecay = 64, 0.25
+	if p != want {
+		t.Errorf("LoadParams gave %v, want %v", p, want)
+	}
+
+	for name, data := range map[string]string{
+		"unknown.json":   `{"opening_beam": 64, "no_such_param": 1}`,
+		"malformed.json": `{"opening_beam": 64`,
+		"type.json":      `{"opening_beam": "wide"}`,
+	} {
+		if _, err := LoadParams(write(name, data)); err == nil {
+			t.Errorf("LoadParams(%s) succeeded", name)
+		}
+	}
+	if _, err := LoadParams(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("LoadParams of a missing file succeeded")
+	}
+}
+
+func TestStrategyParamsAreKeys(t *testing.T) {
+	keys := make(map[string]bool)
+	for _, k := range ParamKeys() {
+		keys[k] = true
+	}
+	for _, name := range StrategyNames() {
+		params, err := StrategyParams(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(params) == 0 {
+			t.Errorf("%s reads no params", name)
+		}
+		for _, k := range params {
+			if !keys[k] {
+				t.Errorf("%s reads unknown param %q", name, k)
+			}
+		}
+	}
+}
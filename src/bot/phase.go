@@ -0,0 +1,208 @@
+package bot
+
+import (
+	"hlt"
+	"log"
+	"reflect"
+)
+
+// Phase is the stage a game has reached from one bot's point of view.
+type Phase int
+
+const (
+	// Opening is free expansion before any enemy is near.
+	Opening Phase = iota
+	// Contact starts when an enemy first comes within ContactDistance of
+	// our territory.
+	Contact
+	// War is a front where a large part of our border touches enemies.
+	War
+	// Endgame is the last stretch before the turn limit, or the time after
+	// the neutral land has run out, when the game is decided by territory
+	// and every capture counts.
+	Endgame
+)
+
+var phaseNames = []string{"opening", "contact", "war", "endgame"}
+
+func (p Phase) String() string {
+	if p < 0 || int(p) >= len(phaseNames) {
+		return "unknown"
+	}
+	return phaseNames[p]
+}
+
+// PhaseHook is implemented by strategies that want to know when the phase
+// changes. EnterPhase is called between turns, never concurrently with
+// Direction.
+type PhaseHook interface {
+	EnterPhase(b *Bot, p Phase)
+}
+
+// Phase returns the phase of the current turn.
+func (b *Bot) Phase() Phase {
+	return b.phase
+}
+
+// TurnNumber returns the number of the current turn, starting at 1.
+func (b *Bot) TurnNumber() int {
+	return b.turn
+}
+
+// updatePhase detects the phase of the current map and tells the strategy if it
+// changed. The game never goes back to an earlier phase, except that a war
+// that quietens down is only contact again.
+func (b *Bot) updatePhase() {
+	p := b.detectPhase()
+	if p < b.phase && !(b.phase == War && p == Contact) {
+		p = b.phase
+	}
+	if p == b.phase {
+		return
+	}
+	log.Printf("Turn %d: %v -> %v", b.turn, b.phase, p)
+	b.phase = p
+	if hook, ok := b.strategy.(PhaseHook); ok {
+		hook.EnterPhase(b, p)
+	}
+}
+
+func (b *Bot) detectPhase() Phase {
+	m := b.gameMap
+	near := enemyDistances(m, b.params.ContactDistance)
+	border, front, neutral, contact := 0, 0, 0, false
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			site := m.Contents[y][x]
+			if site.IsNeutral() {
+				neutral++
+			}
+			if !site.IsMine() {
+				continue
+			}
+			if near[y][x] >= 0 {
+				contact = true
+			}
+			loc := hlt.NewLocation(x, y)
+			if b.hasEnemyNeighbour(loc) {
+				border++
+				if near[y][x] == 1 {
+					front++
+				}
+			}
+		}
+	}
+	limit := b.info.TurnLimit()
+	switch {
+	case limit > 0 && b.turn > limit-limit*b.params.EndgamePercent/100,
+		neutral*100 < m.Width*m.Height*b.params.EndgamePercent:
+		return Endgame
+	case border > 0 && front*100 >= border*b.params.WarFrontPercent:
+		return War
+	case contact:
+		return Contact
+	}
+	return Opening
+}
+
+// enemyDistances returns, for every site of m, the number of steps to the
+// nearest enemy site if it is at most max, and -1 otherwise.
+func enemyDistances(m hlt.GameMap, max int) [][]int {
+	dist := make([][]int, m.Height)
+	var queue []hlt.Location
+	for y := range dist {
+		dist[y] = make([]int, m.Width)
+		for x := range dist[y] {
+			dist[y][x] = -1
+			if site := m.Contents[y][x]; !site.IsMine() && !site.IsNeutral() {
+				dist[y][x] = 0
+				queue = append(queue, hlt.NewLocation(x, y))
+			}
+		}
+	}
+	for len(queue) > 0 {
+		loc := queue[0]
+		queue = queue[1:]
+		d := dist[loc.Y][loc.X]
+		if d == max {
+			continue
+		}
+		for _, direction := range hlt.CARDINALS {
+			next := m.GetLocation(loc, direction)
+			if dist[next.Y][next.X] < 0 {
+				dist[next.Y][next.X] = d + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+	return dist
+}
+
+// Phased plays a different strategy in each phase, falling back to Default
+// for phases without one of their own.
+type Phased struct {
+	Default Strategy
+	Phases  map[Phase]Strategy
+}
+
+func (s Phased) Direction(b *Bot, loc hlt.Location) hlt.Direction {
+	if strategy, ok := s.Phases[b.phase]; ok {
+		return strategy.Direction(b, loc)
+	}
+	return s.Default.Direction(b, loc)
+}
+
+// InitGame prepares every strategy of the game, each once.
+func (s Phased) InitGame(b *Bot) {
+	strategies := append([]Strategy{s.Default}, s.phaseStrategies()...)
+	for i, strategy := range strategies {
+		if hook, ok := strategy.(InitHook); ok && !containsStrategy(strategies[:i], strategy) {
+			hook.InitGame(b)
+		}
+	}
+}
+
+// containsStrategy reports whether s is one of strategies. Strategies whose
+// type cannot be compared, such as structs holding funcs, are never found.
+func containsStrategy(strategies []Strategy, s Strategy) bool {
+	if !reflect.TypeOf(s).Comparable() {
+		return false
+	}
+	for _, other := range strategies {
+		if other == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (s Phased) BeginTurn(b *Bot) {
+	strategy, ok := s.Phases[b.phase]
+	if !ok {
+		strategy = s.Default
+	}
+	if hook, ok := strategy.(TurnHook); ok {
+		hook.BeginTurn(b)
+	}
+}
+
+// phaseStrategies lists the per-phase strategies in phase order.
+func (s Phased) phaseStrategies() []Strategy {
+	var strategies []Strategy
+	for p := Opening; p <= Endgame; p++ {
+		if strategy, ok := s.Phases[p]; ok {
+			strategies = append(strategies, strategy)
+		}
+	}
+	return strategies
+}
+
+func (s Phased) EnterPhase(b *Bot, p Phase) {
+	strategy, ok := s.Phases[p]
+	if !ok {
+		strategy = s.Default
+	}
+	if hook, ok := strategy.(PhaseHook); ok {
+		hook.EnterPhase(b, p)
+	}
+}
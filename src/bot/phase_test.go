@@ -0,0 +1,94 @@
+package bot
+
+import (
+	"hlt"
+	"testing"
+)
+
+func TestDetectPhase(t *testing.T) {
+	for _, tc := range []struct {
+		name, owners string
+		turn         int
+		want         Phase
+	}{
+		{"alone", `
+. . . . .
+. . 1 . .
+. . . . .
+. . . . .
+. . . . .`, 1, Opening},
+		{"enemy near", `
+. . . . .
+. . 1 . .
+. . . . .
+. . 2 . .
+. . . . .`, 1, Contact},
+		{"front", `
+. . . . .
+. . 1 . .
+. . 2 . .
+. . . . .
+. . . . .`, 1, War},
+		{"turn limit", `
+. . . . .
+. . 1 . .
+. . . . .
+. . . . .
+. . . . .`, 1000, Endgame},
+		{"no neutral land", `
+1 1 1 2 2
+1 1 1 2 2
+1 1 1 2 2
+1 1 . 2 2
+1 1 1 2 2`, 1, Endgame},
+	} {
+		f, err := hlt.ParseFixture("me 1\nowner\n" + tc.owners)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := New(mybot(), 1)
+		b.Init(f.Info())
+		b.gameMap, b.turn = f.Map, tc.turn
+		if got := b.detectPhase(); got != tc.want {
+			t.Errorf("%s: phase %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+// initCounter counts InitGame calls. The slice makes it unhashable.
+type initCounter struct {
+	calls *int
+	_     []int
+}
+
+func (c initCounter) Direction(b *Bot, loc hlt.Location) hlt.Direction {
+	return hlt.STILL
+}
+
+func (c initCounter) InitGame(b *Bot) {
+	*c.calls++
+}
+
+func TestPhasedInitGame(t *testing.T) {
+	var unhashable, shared int
+	s := &openingPlanner{fallback: initCounter{calls: &shared}}
+	p := Phased{
+		Default: s,
+		Phases: map[Phase]Strategy{
+			Opening: s,
+			War:     initCounter{calls: &unhashable},
+			Endgame: initCounter{calls: &unhashable},
+		},
+	}
+	f, err := hlt.ParseFixture("me 1\nowner\n. 1\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	New(p, 1).Init(f.Info())
+	if shared != 1 {
+		t.Errorf("shared strategy initialised %d times, want 1", shared)
+	}
+	if unhashable != 2 {
+		t.Errorf("unhashable strategies initialised %d times, want 2", unhashable)
+	}
+}
@@ -0,0 +1,109 @@
+package bot
+
+import (
+	"hlt"
+	"log"
+	"sort"
+)
+
+// maxStrength is the most a site can hold; anything above is lost.
+const maxStrength = 255
+
+// reinforcer plays fallback on the border, but plans the whole turn when it
+// begins so that interior pieces, the ones with only our own sites around
+// them, move knowing where every piece they could merge with goes.
+type reinforcer struct {
+	fallback Strategy
+	moves    moveMap
+}
+
+// BeginTurn decides the border pieces with fallback first, then the interior
+// in order of distance to the border, nearest first. A piece's neighbours
+// nearer the border have been decided by then; the others have not and are
+// counted as staying, which is the most they can add to a merge.
+func (r *reinforcer) BeginTurn(b *Bot) {
+	if hook, ok := r.fallback.(TurnHook); ok {
+		hook.BeginTurn(b)
+	}
+	m, flow := b.gameMap, b.BorderFlow()
+	r.moves = make(moveMap)
+	// arriving is the strength moving onto each site this turn.
+	arriving := make(map[hlt.Location]int)
+	move := func(loc hlt.Location, d hlt.Direction) {
+		r.moves[loc] = d
+		if d != hlt.STILL {
+			arriving[m.GetLocation(loc, d)] += b.getStrength(loc)
+		}
+	}
+	var interior []hlt.Location
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			loc := hlt.NewLocation(x, y)
+			switch {
+			case !m.Contents[y][x].IsMine():
+			case flow.Distance(loc) == 0:
+				move(loc, r.fallback.Direction(b, loc))
+			default:
+				interior = append(interior, loc)
+			}
+		}
+	}
+	sort.SliceStable(interior, func(i, j int) bool {
+		return flow.Distance(interior[i]) < flow.Distance(interior[j])
+	})
+	// tripCost is the production a piece gives up on its way to the border:
+	// that of every site it leaves, one turn each.
+	tripCost := make(map[hlt.Location]int)
+	for _, loc := range interior {
+		site := m.GetSite(loc, hlt.STILL)
+		tripCost[loc] = site.Production + tripCost[m.GetLocation(loc, flow.Directions(m, loc)[0])]
+		move(loc, r.reinforce(b, loc, tripCost[loc], arriving))
+	}
+}
+
+// reinforce decides the move of an interior piece. It keeps the piece
+// producing until its strength is worth ReinforceMultiple times the
+// production its trip gives up, or until it is about to reach the cap, so
+// pieces far from the border set out full. It then heads for an enemy in
+// sight or the production cluster assigned to its part of the border, or
+// else down the border flow field, taking the step where it merges without
+// going over the cap. When every step would waste strength it waits, unless
+// waiting would waste production instead.
+func (r *reinforcer) reinforce(b *Bot, loc hlt.Location, tripCost int, arriving map[hlt.Location]int) hlt.Direction {
+	site := b.gameMap.GetSite(loc, hlt.STILL)
+	overflowing := site.Strength+site.Production > maxStrength
+	if site.Strength == 0 || (!overflowing && site.Strength < tripCost*b.params.ReinforceMultiple) {
+		return hlt.STILL
+	}
+
+	directions := b.getClosestEnemy(loc)
+	if len(directions) == 0 {
+		directions = b.Targets().Directions(b.gameMap, loc)
+	}
+	if len(directions) == 0 {
+		directions = b.BorderFlow().Directions(b.gameMap, loc)
+	}
+	best, bestStrength := hlt.STILL, maxStrength+1
+	for _, d := range directions {
+		next := b.gameMap.GetLocation(loc, d)
+		merged := site.Strength + arriving[next]
+		if planned, ok := r.moves[next]; !ok || planned == hlt.STILL {
+			merged += b.getStrength(next)
+		}
+		if merged < bestStrength {
+			best, bestStrength = d, merged
+		}
+	}
+	if best == hlt.STILL || (bestStrength > maxStrength && !overflowing) {
+		return hlt.STILL
+	}
+	log.Println("Reinforcing the border")
+	return best
+}
+
+func (r *reinforcer) Direction(b *Bot, loc hlt.Location) hlt.Direction {
+	if d, ok := r.moves[loc]; ok {
+		return d
+	}
+	return r.fallback.Direction(b, loc)
+}
@@ -0,0 +1,128 @@
+package bot
+
+import (
+	"hlt"
+	"testing"
+)
+
+func reinforceMoves(t *testing.T, text string) map[hlt.Location]hlt.Direction {
+	f, err := hlt.ParseFixture(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewStrategy("reinforce")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := New(s, 1)
+	b.Init(f.Info())
+	moves := make(map[hlt.Location]hlt.Direction)
+	for _, mv := range b.Turn(f.Map) {
+		moves[mv.Location] = mv.Direction
+	}
+	return moves
+}
+
+// The border pieces around the centre cannot move, so the centre would
+// merge over the cap wherever it went.
+func TestReinforceWaitsForBorderToMove(t *testing.T) {
+	moves := reinforceMoves(t, `
+me 1
+owner
+  .   .   .   .   .
+  .   1   1   1   .
+  .   1   1   1   .
+  .   1   1   1   .
+  .   .   .   .   .
+strength
+255 255 255 255 255
+255 100 100 100 255
+255 100 200 100 255
+255 100 100 100 255
+255 255 255 255 255
+production
+  0   0   0   0   0
+  0   1   1   1   0
+  0   1   1   1   0
+  0   1   1   1   0
+  0   0   0   0   0
+`)
+	if d := moves[hlt.NewLocation(2, 2)]; d != hlt.STILL {
+		t.Errorf("centre moved %v onto a piece that stays", d)
+	}
+}
+
+// The north border piece captures the weak site beyond it, leaving room for
+// the centre.
+func TestReinforceFollowsBorderMove(t *testing.T) {
+	moves := reinforceMoves(t, `
+me 1
+owner
+  .   .   .   .   .
+  .   1   1   1   .
+  .   1   1   1   .
+  .   1   1   1   .
+  .   .   .   .   .
+strength
+255 255   5 255 255
+255 100 100 100 255
+255 100 200 100 255
+255 100 100 100 255
+255 255 255 255 255
+production
+  0   0   1   0   0
+  0   1   1   1   0
+  0   1   1   1   0
+  0   1   1   1   0
+  0   0   0   0   0
+`)
+	if d := moves[hlt.NewLocation(2, 1)]; d != hlt.NORTH {
+		t.Fatalf("north border piece moved %v, want NORTH", d)
+	}
+	if d := moves[hlt.NewLocation(2, 2)]; d != hlt.NORTH {
+		t.Errorf("centre moved %v, want NORTH", d)
+	}
+}
+
+// Pieces far from the border wait for more strength than those next to it.
+func TestReinforceTimingByDistance(t *testing.T) {
+	moves := reinforceMoves(t, `
+me 1
+owner
+  .   .   .   .   .   .   .   .   .
+  .   1   1   1   1   1   1   1   .
+  .   1   1   1   1   1   1   1   .
+  .   1   1   1   1   1   1   1   .
+  .   1   1   1   1   1   1   1   .
+  .   1   1   1   1   1   1   1   .
+  .   1   1   1   1   1   1   1   .
+  .   1   1   1   1   1   1   1   .
+  .   .   .   .   .   .   .   .   .
+strength
+255 255 255 255 255 255 255 255 255
+255  10  10  10  10  10  10  10 255
+255  10  40  40  40  40  40  10 255
+255  10  40  40  40  40  40  10 255
+255  10  40  40  40  40  40  10 255
+255  10  40  40  40  40  40  10 255
+255  10  40  40  40  40  40  10 255
+255  10  10  10  10  10  10  10 255
+255 255 255 255 255 255 255 255 255
+production
+  0   0   0   0   0   0   0   0   0
+  0   3   3   3   3   3   3   3   0
+  0   3   3   3   3   3   3   3   0
+  0   3   3   3   3   3   3   3   0
+  0   3   3   3   3   3   3   3   0
+  0   3   3   3   3   3   3   3   0
+  0   3   3   3   3   3   3   3   0
+  0   3   3   3   3   3   3   3   0
+  0   0   0   0   0   0   0   0   0
+`)
+	if d := moves[hlt.NewLocation(2, 4)]; d != hlt.WEST {
+		t.Errorf("piece next to the border moved %v, want WEST", d)
+	}
+	if d := moves[hlt.NewLocation(4, 4)]; d != hlt.STILL {
+		t.Errorf("centre moved %v, want STILL", d)
+	}
+}
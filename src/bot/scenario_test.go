@@ -0,0 +1,58 @@
+package bot
+
+import (
+	"bytes"
+	"flag"
+	"hlt"
+	"io/ioutil"
+	"log"
+	"os"
+	"scenario"
+	"testing"
+	"time"
+)
+
+var seed = flag.Int64("seed", 1, "Seed for the bots' random choices in the scenarios, 0 picks one from the clock")
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	log.SetOutput(ioutil.Discard)
+	os.Exit(m.Run())
+}
+
+// TestScenarios checks every strategy but random against the scenario files.
+// The scenarios allow every move a sensible bot might pick, so a failure
+// with one seed is a bug whatever the seed.
+func TestScenarios(t *testing.T) {
+	scenarios, err := scenario.LoadDir("../../scenarios")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scenarios) == 0 {
+		t.Fatal("no scenarios")
+	}
+	s := *seed
+	if s == 0 {
+		s = time.Now().UnixNano()
+	}
+	for _, name := range StrategyNames() {
+		if name == "random" {
+			continue
+		}
+		var out bytes.Buffer
+		failed := scenario.Run(scenarios, func(sc scenario.Scenario) func(hlt.Location) hlt.Direction {
+			strategy, err := NewStrategy(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b := New(strategy, s)
+			b.Init(sc.Fixture.Info())
+			return func(loc hlt.Location) hlt.Direction {
+				return b.Decide(sc.Fixture.Map, sc.Previous, loc)
+			}
+		}, &out)
+		if failed > 0 {
+			t.Errorf("%s failed %d scenarios, reproduce with -seed %d\n%s", name, failed, s, out.String())
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package bot
+
+import (
+	"fmt"
+	"hlt"
+	"log"
+	"sort"
+)
+
+// Strategy decides the move of a single piece. Strategies read the game
+// through the Bot and keep any state of their own; the registry creates a
+// fresh one for every bot. Direction is called from several goroutines at
+// once during a turn, so it must not modify shared state without locking.
+type Strategy interface {
+	Direction(b *Bot, loc hlt.Location) hlt.Direction
+}
+
+// InitHook is implemented by strategies that prepare for a game once the
+// bot knows the initial map. InitGame is called from Bot.Init, which MyBot
+// runs before sending its name, and should finish by Bot.InitDeadline.
+type InitHook interface {
+	InitGame(b *Bot)
+}
+
+// TurnHook is implemented by strategies that plan a whole turn before the
+// pieces are decided. BeginTurn is called once per turn, after the phase is
+// updated and before any Direction call.
+type TurnHook interface {
+	BeginTurn(b *Bot)
+}
+
+type registration struct {
+	newStrategy func() Strategy
+	params      []string
+}
+
+var registry = make(map[string]registration)
+
+// Register makes a strategy available under a name, along with the keys of
+// the parameters it reads. It panics if the name is already taken.
+func Register(name string, newStrategy func() Strategy, params ...string) {
+	if _, ok := registry[name]; ok {
+		panic("bot: strategy " + name + " registered twice")
+	}
+	registry[name] = registration{newStrategy, params}
+}
+
+func NewStrategy(name string) (Strategy, error) {
+	r, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown strategy %q, have %v", name, StrategyNames())
+	}
+	return r.newStrategy(), nil
+}
+
+// StrategyParams lists the keys of the parameters the named strategy reads,
+// so tuning can leave the others alone.
+func StrategyParams(name string) ([]string, error) {
+	r, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown strategy %q, have %v", name, StrategyNames())
+	}
+	return append([]string(nil), r.params...), nil
+}
+
+func StrategyNames() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// with returns keys followed by more without sharing keys' backing array.
+func with(keys []string, more ...string) []string {
+	return append(append([]string(nil), keys...), more...)
+}
+
+func init() {
+	// cascadeParams are read by the cascade with its original rules.
+	cascadeParams := []string{"hold_production_multiple", "roam_min_territory", "roam_min_strength", "enemy_scan_max_strength", "neutral_distance_penalty"}
+	phaseParams := []string{"contact_distance", "war_front_percent", "endgame_percent"}
+	heatParams := with(cascadeParams, "heat_radius", "heat_decay")
+	Register("mybot", func() Strategy {
+		return mybot()
+	}, cascadeParams...)
+	// planner follows a searched capture order in the opening and plays
+	// mybot after that.
+	Register("planner", func() Strategy {
+		return Phased{
+			Default: mybot(),
+			Phases: map[Phase]Strategy{
+				Opening: &openingPlanner{fallback: mybot()},
+			},
+		}
+	}, with(with(cascadeParams, phaseParams...), "opening_turns", "opening_radius", "opening_beam")...)
+	// phased plays mybot's cascade, but at war lets strong pieces roam
+	// whatever the territory size, and in the endgame pushes for territory:
+	// every piece heads out and the border takes the cheapest sites.
+	Register("phased", func() Strategy {
+		war := mybot()
+		war.roam = roamWhenStrong
+		endgame := mybot()
+		endgame.roam = roamAlways
+		endgame.neutralNeighbours = (*Bot).getWeakestDefeatableNeighbour
+		return Phased{
+			Default: mybot(),
+			Phases: map[Phase]Strategy{
+				War:     war,
+				Endgame: endgame,
+			},
+		}
+	}, with(cascadeParams, phaseParams...)...)
+	// heat is mybot capturing the neutral neighbours the heatmap values
+	// most.
+	Register("heat", func() Strategy {
+		c := mybot()
+		c.neutralNeighbours = (*Bot).getHottestNeutralNeighbours
+		return c
+	}, heatParams...)
+	// cluster is heat sending roaming pieces to the production clusters
+	// assigned to their part of the border instead of scanning for neutral
+	// land in straight lines.
+	Register("cluster", func() Strategy {
+		c := mybot()
+		c.neutralNeighbours = (*Bot).getHottestNeutralNeighbours
+		c.neutralDirections = (*Bot).getClusterDirections
+		return c
+	}, with(heatParams, "cluster_percent", "cluster_targets")...)
+	// reinforce is heat moving interior pieces to the border on a schedule
+	// rather than roaming them.
+	Register("reinforce", func() Strategy {
+		c := mybot()
+		c.neutralNeighbours = (*Bot).getHottestNeutralNeighbours
+		return &reinforcer{fallback: c}
+	}, with(heatParams, "cluster_percent", "cluster_targets", "reinforce_multiple")...)
+	Register("random", func() Strategy {
+		return cascade{
+			opponentDirections: (*Bot).getOpponentOrWeakNeutralDirections,
+			canEnter:           enterOwnOrWeaker,
+		}
+	}, with(cascadeParams, "weak_neutral_strength")...)
+}
+
+func mybot() cascade {
+	return cascade{
+		opponentDirections: (*Bot).getOpponentDirections,
+		canEnter:           enterOwnEnemyOrWeaker,
+	}
+}
+
+// cascade is the decision order MyBot and RandomBot share: attack, capture a
+// neutral neighbour, then move strong pieces towards enemies or neutral land.
+// The bots differ in which neighbours they attack first and where pieces may
+// move.
+type cascade struct {
+	opponentDirections func(b *Bot, loc hlt.Location) []hlt.Direction
+	canEnter           enterRule
+	// roam decides whether a piece with nothing to capture next to it moves
+	// on; nil is MyBot's original rule.
+	roam roamRule
+	// neutralNeighbours picks the neutral neighbours worth capturing and
+	// neutralDirections where to head for neutral land further away; nil
+	// uses MyBot's site values.
+	neutralNeighbours, neutralDirections func(b *Bot, loc hlt.Location) []hlt.Direction
+}
+
+type roamRule func(b *Bot, loc hlt.Location) bool
+
+// roamWhenHeld lets a piece go once it has held its site for a few turns'
+// worth of production.
+func roamWhenHeld(b *Bot, loc hlt.Location) bool {
+	site := b.gameMap.GetSite(loc, hlt.STILL)
+	return site.Production*b.params.HoldProductionMultiple < site.Strength
+}
+
+// roamWhenStrong also lets strong pieces go however rich their site is.
+func roamWhenStrong(b *Bot, loc hlt.Location) bool {
+	return roamWhenHeld(b, loc) || b.getStrength(loc) > b.params.RoamMinStrength
+}
+
+// roamAlways lets every piece go, for when holding on to grow no longer
+// pays off.
+func roamAlways(b *Bot, loc hlt.Location) bool {
+	return true
+}
+
+func roamOriginal(b *Bot, loc hlt.Location) bool {
+	return roamWhenHeld(b, loc) ||
+		(len(b.lastMoves) > b.params.RoamMinTerritory && b.getStrength(loc) > b.params.RoamMinStrength)
+}
+
+func enterOwnEnemyOrWeaker(b *Bot, loc hlt.Location, d hlt.Direction) bool {
+	destinationLocation := b.gameMap.GetLocation(loc, d)
+	return (b.gameMap.GetSite(loc, d).IsMine() || !b.gameMap.GetSite(loc, d).IsNeutral()) || b.getStrength(loc) > b.getStrength(destinationLocation)
+}
+
+func enterOwnOrWeaker(b *Bot, loc hlt.Location, d hlt.Direction) bool {
+	destinationLocation := b.gameMap.GetLocation(loc, d)
+	return b.gameMap.GetSite(loc, d).IsMine() || b.getStrength(loc) > b.getStrength(destinationLocation)
+}
+
+func (c cascade) Direction(b *Bot, fromLocation hlt.Location) hlt.Direction {
+	locationStrength := b.getStrength(fromLocation)
+	if locationStrength < 1 {
+		return hlt.STILL
+	}
+	opponentNeighbours := c.opponentDirections(b, fromLocation)
+	if len(opponentNeighbours) > 0 {
+		log.Println("Moving onto opponent")
+		return b.pickRandomNonReversedDirection(fromLocation, opponentNeighbours, c.canEnter)
+	}
+	neutralNeighbours := c.neutralNeighbours
+	if neutralNeighbours == nil {
+		neutralNeighbours = (*Bot).getHighestValueNeutralNeighbours
+	}
+	defeatableNeighbours := neutralNeighbours(b, fromLocation)
+
+	if len(defeatableNeighbours) > 0 {
+		log.Println("Conquoring a neutral")
+		return b.pickRandomNonReversedDirection(fromLocation, defeatableNeighbours, c.canEnter)
+	}
+
+	roam := c.roam
+	if roam == nil {
+		roam = roamOriginal
+	}
+	if roam(b, fromLocation) {
+		visibleCloseEnemies := b.getClosestEnemy(fromLocation)
+		if len(visibleCloseEnemies) > 0 {
+			log.Println("Moving towards enemy")
+			return b.pickRandomNonReversedDirection(fromLocation, visibleCloseEnemies, c.canEnter)
+		}
+		neutralDirections := c.neutralDirections
+		if neutralDirections == nil {
+			neutralDirections = (*Bot).getMostValuableNeutralDirections
+		}
+		visibleNeutralDirections := neutralDirections(b, fromLocation)
+		if len(visibleNeutralDirections) > 0 {
+			log.Println("Moving towards neutral")
+			return b.pickRandomNonReversedDirection(fromLocation, visibleNeutralDirections, c.canEnter)
+		}
+		log.Println("Moving at random")
+		if b.hasOnlyFriendlyNeighbours(fromLocation) {
+			return b.pickRandomNonReversedDirection(fromLocation, hlt.Directions, c.canEnter)
+		}
+	}
+	return hlt.STILL
+}
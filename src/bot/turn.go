@@ -0,0 +1,96 @@
+package bot
+
+import (
+	"hlt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+)
+
+// chunkSize is the number of pieces a worker decides at a time. Chunks are
+// fixed slices of the owned sites in row order, each with its own random
+// source seeded from the bot's, so the moves are the same however the chunks
+// are spread over workers.
+const chunkSize = 64
+
+// Turn decides a move for every site the bot owns and returns them in row
+// order. The map must be seen from the bot's player, as the Connection
+// delivers it.
+func (b *Bot) Turn(m hlt.GameMap) hlt.MoveSet {
+	b.gameMap = m
+	b.lastMoves = b.currentMoves
+	b.turn++
+	b.analyse()
+	if hook, ok := b.strategy.(TurnHook); ok {
+		hook.BeginTurn(b)
+	}
+
+	owned := make([]hlt.Location, 0)
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if m.Contents[y][x].IsMine() {
+				owned = append(owned, hlt.NewLocation(x, y))
+			}
+		}
+	}
+	moves := make(hlt.MoveSet, len(owned))
+	chunks := (len(owned) + chunkSize - 1) / chunkSize
+	seeds := make([]int64, chunks)
+	for c := range seeds {
+		seeds[c] = b.rng.Int63()
+	}
+
+	// Workers claim chunks through an atomic counter and write only their
+	// own chunk's part of moves, so no locking is needed.
+	next := int64(-1)
+	work := func() {
+		for {
+			c := int(atomic.AddInt64(&next, 1))
+			if c >= chunks {
+				return
+			}
+			b.decideChunk(owned, moves, c, seeds[c])
+		}
+	}
+	workers := b.workers
+	if workers > chunks {
+		workers = chunks
+	}
+	if workers <= 1 {
+		work()
+	} else {
+		var wg sync.WaitGroup
+		wg.Add(workers)
+		for w := 0; w < workers; w++ {
+			go func() {
+				defer wg.Done()
+				work()
+			}()
+		}
+		wg.Wait()
+	}
+
+	b.currentMoves = make(moveMap, len(moves))
+	for _, mv := range moves {
+		b.currentMoves[mv.Location] = mv.Direction
+	}
+	return moves
+}
+
+// decideChunk fills moves for chunk c of owned. It decides through a copy of
+// the bot carrying the chunk's random source; everything else the copy shares
+// is only read during a turn.
+func (b *Bot) decideChunk(owned []hlt.Location, moves hlt.MoveSet, c int, seed int64) {
+	worker := *b
+	worker.rng = rand.New(rand.NewSource(seed))
+	end := (c + 1) * chunkSize
+	if end > len(owned) {
+		end = len(owned)
+	}
+	for i := c * chunkSize; i < end; i++ {
+		moves[i] = hlt.Move{
+			Location:  owned[i],
+			Direction: b.strategy.Direction(&worker, owned[i]),
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package bot
+
+import (
+	"fmt"
+	"hlt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// benchMap builds a size x size map where player 1 owns the first owned
+// sites in row order, player 2 a block in the far corner and the rest is
+// neutral, with random strengths and productions.
+func benchMap(size, owned int, seed int64) hlt.Fixture {
+	rng := rand.New(rand.NewSource(seed))
+	m := hlt.NewGameMap(size, size)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			site := &m.Contents[y][x]
+			site.Production = 1 + rng.Intn(10)
+			site.Strength = rng.Intn(256)
+			switch {
+			case y*size+x < owned:
+				site.Owner = 1
+			case x >= size*3/4 && y >= size*3/4:
+				site.Owner = 2
+			}
+		}
+	}
+	m.SetPlayer(1)
+	return hlt.Fixture{Me: 1, Map: m}
+}
+
+// BenchmarkTurn measures how long mybot takes to decide a turn on a 50x50
+// map where it owns 2000 sites, with different numbers of workers.
+func BenchmarkTurn(b *testing.B) {
+	f := benchMap(50, 2000, 1)
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			bot := New(mybot(), 1)
+			bot.Init(f.Info())
+			bot.SetWorkers(workers)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				bot.Turn(f.Map)
+			}
+		})
+	}
+}
+
+func TestTurnIndependentOfWorkers(t *testing.T) {
+	f := benchMap(30, 600, 2)
+	var want hlt.MoveSet
+	for _, workers := range []int{1, 3, 8} {
+		b := New(mybot(), 1)
+		b.Init(f.Info())
+		b.SetWorkers(workers)
+		got := b.Turn(f.Map)
+		if want == nil {
+			want = got
+		} else if !reflect.DeepEqual(got, want) {
+			t.Errorf("moves with %d workers differ from 1 worker", workers)
+		}
+	}
+}
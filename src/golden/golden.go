@@ -0,0 +1,160 @@
+// Package golden records the moves a bot makes on a corpus of stored frames
+// and reports every cell whose decision changed since the golden files were
+// last updated.
+//
+// A corpus directory holds frames as hlt fixtures in NAME.txt with the
+// recorded moves next to them in NAME.golden, one "x y DIR" line per owned
+// cell in row order.
+package golden
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"hlt"
+)
+
+// Turn computes the bot's moves for a frame. It must be deterministic for
+// golden files to be stable.
+type Turn func(f hlt.Fixture) hlt.MoveSet
+
+type change struct {
+	loc       hlt.Location
+	was, now  hlt.Direction
+	gone, new bool
+}
+
+func Format(moves hlt.MoveSet) string {
+	sorted := append(hlt.MoveSet(nil), moves...)
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i].Location, sorted[j].Location
+		return a.Y < b.Y || (a.Y == b.Y && a.X < b.X)
+	})
+	var buf bytes.Buffer
+	for _, mv := range sorted {
+		fmt.Fprintf(&buf, "%d %d %v\n", mv.Location.X, mv.Location.Y, mv.Direction)
+	}
+	return buf.String()
+}
+
+func Parse(text string) (hlt.MoveSet, error) {
+	var moves hlt.MoveSet
+	for n, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("line %d: want \"x y direction\"", n+1)
+		}
+		x, errX := strconv.Atoi(fields[0])
+		y, errY := strconv.Atoi(fields[1])
+		d, errD := hlt.ParseDirection(fields[2])
+		if errX != nil || errY != nil || errD != nil {
+			return nil, fmt.Errorf("line %d: bad move %q", n+1, line)
+		}
+		moves = append(moves, hlt.Move{Location: hlt.NewLocation(x, y), Direction: d})
+	}
+	return moves, nil
+}
+
+func diff(want, got hlt.MoveSet) []change {
+	was := make(map[hlt.Location]hlt.Direction, len(want))
+	for _, mv := range want {
+		was[mv.Location] = mv.Direction
+	}
+	var changes []change
+	for _, mv := range got {
+		d, ok := was[mv.Location]
+		switch {
+		case !ok:
+			changes = append(changes, change{loc: mv.Location, now: mv.Direction, new: true})
+		case d != mv.Direction:
+			changes = append(changes, change{loc: mv.Location, was: d, now: mv.Direction})
+		}
+		delete(was, mv.Location)
+	}
+	for loc, d := range was {
+		changes = append(changes, change{loc: loc, was: d, gone: true})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i].loc, changes[j].loc
+		return a.Y < b.Y || (a.Y == b.Y && a.X < b.X)
+	})
+	return changes
+}
+
+// Check runs turn over every frame in dir. With update set it rewrites the
+// golden files instead of comparing against them. It writes a report to out
+// and returns the number of frames whose moves changed.
+func Check(dir string, update bool, turn Turn, out io.Writer) (int, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		return 0, err
+	}
+	sort.Strings(paths)
+	failed := 0
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".txt")
+		goldenPath := strings.TrimSuffix(path, ".txt") + ".golden"
+		text, err := ioutil.ReadFile(path)
+		if err != nil {
+			return failed, err
+		}
+		f, err := hlt.ParseFixture(string(text))
+		if err != nil {
+			return failed, fmt.Errorf("%s: %v", path, err)
+		}
+		got := turn(f)
+
+		if update {
+			if err := ioutil.WriteFile(goldenPath, []byte(Format(got)), 0644); err != nil {
+				return failed, err
+			}
+			fmt.Fprintf(out, "updated %s (%d moves)\n", name, len(got))
+			continue
+		}
+
+		wantText, err := ioutil.ReadFile(goldenPath)
+		if os.IsNotExist(err) {
+			failed++
+			fmt.Fprintf(out, "FAIL %s: no golden file, run with -update\n", name)
+			continue
+		} else if err != nil {
+			return failed, err
+		}
+		want, err := Parse(string(wantText))
+		if err != nil {
+			return failed, fmt.Errorf("%s: %v", goldenPath, err)
+		}
+		changes := diff(want, got)
+		if len(changes) == 0 {
+			fmt.Fprintf(out, "ok   %s\n", name)
+			continue
+		}
+		failed++
+		fmt.Fprintf(out, "FAIL %s: %d of %d moves changed\n", name, len(changes), len(got))
+		var highlight []hlt.Location
+		for _, c := range changes {
+			switch {
+			case c.new:
+				fmt.Fprintf(out, "     (%d,%d) new move %v\n", c.loc.X, c.loc.Y, c.now)
+			case c.gone:
+				fmt.Fprintf(out, "     (%d,%d) no longer moves, was %v\n", c.loc.X, c.loc.Y, c.was)
+			default:
+				fmt.Fprintf(out, "     (%d,%d) %v -> %v\n", c.loc.X, c.loc.Y, c.was, c.now)
+			}
+			highlight = append(highlight, c.loc)
+		}
+		fmt.Fprint(out, f.Map.Render(hlt.RenderOptions{Moves: got, Highlight: highlight}))
+	}
+	fmt.Fprintf(out, "%d/%d frames unchanged\n", len(paths)-failed, len(paths))
+	return failed, nil
+}
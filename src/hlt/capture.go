@@ -0,0 +1,100 @@
+package hlt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ReadCapture decodes a recording of everything the engine sent to one bot:
+// the player tag, map size, productions and initial map, followed by one map
+// per turn. The returned frames are the per-turn maps; the first is the same
+// board as the initial map the GameInfo is derived from. Such a capture can
+// be taken by running the bot as "tee capture.txt | ./mybot".
+func ReadCapture(r io.Reader) (GameInfo, []GameMap, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	nextLine := func() (string, bool) {
+		for scanner.Scan() {
+			line++
+			if text := strings.TrimSpace(scanner.Text()); text != "" {
+				return text, true
+			}
+		}
+		return "", false
+	}
+	ints := func(text string, want int) ([]int, error) {
+		fields := strings.Fields(text)
+		if len(fields) != want {
+			return nil, fmt.Errorf("capture line %d: %d values, want %d", line, len(fields), want)
+		}
+		values := make([]int, len(fields))
+		for i, f := range fields {
+			v, err := strconv.Atoi(f)
+			if err != nil {
+				return nil, fmt.Errorf("capture line %d: %v", line, err)
+			}
+			values[i] = v
+		}
+		return values, nil
+	}
+
+	var header [3]string
+	for i := range header {
+		text, ok := nextLine()
+		if !ok {
+			return GameInfo{}, nil, fmt.Errorf("capture: truncated header: %v", scanner.Err())
+		}
+		header[i] = text
+	}
+	tag, err := ints(header[0], 1)
+	if err != nil {
+		return GameInfo{}, nil, err
+	}
+	size, err := ints(header[1], 2)
+	if err != nil {
+		return GameInfo{}, nil, err
+	}
+	width, height := size[0], size[1]
+	flat, err := ints(header[2], width*height)
+	if err != nil {
+		return GameInfo{}, nil, err
+	}
+	productions := make([][]int, height)
+	for y := range productions {
+		productions[y] = flat[y*width : (y+1)*width]
+	}
+
+	initial, ok := nextLine()
+	if !ok {
+		return GameInfo{}, nil, fmt.Errorf("capture: no initial map")
+	}
+	m, err := parseFrame(initial, width, height, productions, PlayerID(tag[0]))
+	if err != nil {
+		return GameInfo{}, nil, fmt.Errorf("capture line %d: %v", line, err)
+	}
+	info := NewGameInfo(PlayerID(tag[0]), m)
+
+	var frames []GameMap
+	for {
+		text, ok := nextLine()
+		if !ok {
+			break
+		}
+		m, err := parseFrame(text, width, height, productions, PlayerID(tag[0]))
+		if err != nil {
+			return GameInfo{}, nil, fmt.Errorf("capture line %d: %v", line, err)
+		}
+		frames = append(frames, m)
+	}
+	if err := scanner.Err(); err != nil {
+		return GameInfo{}, nil, err
+	}
+	if len(frames) == 0 {
+		return GameInfo{}, nil, fmt.Errorf("capture: no frames")
+	}
+	return info, frames, nil
+}
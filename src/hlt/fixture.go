@@ -0,0 +1,167 @@
+package hlt
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Fixture is a board described as text, for tests and scenario files. The
+// format is a "me" line naming the player the map is seen from, followed by
+// up to three grids of whitespace separated cells, each introduced by its
+// name on a line of its own:
+//
+//	me 1
+//	owner
+//	.  .  1  .
+//	.  2  1  .
+//	strength
+//	0 10 200  5
+//	0 30  50  5
+//	production
+//	1  1  2  3
+//	1  1  2  3
+//
+// Owners are player numbers, with '.' for neutral. The owner grid is
+// required and sets the map size; missing strength or production grids are
+// all zero. Blank lines and lines starting with '#' are ignored.
+type Fixture struct {
+	Me  PlayerID
+	Map GameMap
+}
+
+var fixtureSections = []string{"owner", "strength", "production"}
+
+func ParseFixture(text string) (Fixture, error) {
+	var f Fixture
+	grids := make(map[string][][]string)
+	section := ""
+	for n, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		switch {
+		case fields[0] == "me":
+			if len(fields) != 2 {
+				return f, fmt.Errorf("fixture line %d: want \"me <player>\"", n+1)
+			}
+			me, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return f, fmt.Errorf("fixture line %d: %v", n+1, err)
+			}
+			f.Me = PlayerID(me)
+		case len(fields) == 1 && isFixtureSection(fields[0]):
+			section = fields[0]
+			if _, ok := grids[section]; ok {
+				return f, fmt.Errorf("fixture line %d: duplicate %s grid", n+1, section)
+			}
+			grids[section] = nil
+		case section == "":
+			return f, fmt.Errorf("fixture line %d: cells before a grid name", n+1)
+		default:
+			grids[section] = append(grids[section], fields)
+		}
+	}
+
+	owners, ok := grids["owner"]
+	if !ok || len(owners) == 0 {
+		return f, fmt.Errorf("fixture: no owner grid")
+	}
+	f.Map = NewGameMap(len(owners[0]), len(owners))
+	for _, name := range fixtureSections {
+		rows, ok := grids[name]
+		if !ok {
+			continue
+		}
+		if len(rows) != f.Map.Height {
+			return f, fmt.Errorf("fixture: %s grid has %d rows, want %d", name, len(rows), f.Map.Height)
+		}
+		for y, row := range rows {
+			if len(row) != f.Map.Width {
+				return f, fmt.Errorf("fixture: %s row %d has %d cells, want %d", name, y, len(row), f.Map.Width)
+			}
+			for x, cell := range row {
+				value := 0
+				if !(name == "owner" && cell == ".") {
+					v, err := strconv.Atoi(cell)
+					if err != nil {
+						return f, fmt.Errorf("fixture: %s row %d: %v", name, y, err)
+					}
+					value = v
+				}
+				site := &f.Map.Contents[y][x]
+				switch name {
+				case "owner":
+					site.Owner = PlayerID(value)
+				case "strength":
+					site.Strength = value
+				case "production":
+					site.Production = value
+				}
+			}
+		}
+	}
+	f.Map.SetPlayer(f.Me)
+	return f, nil
+}
+
+// MustParseFixture is ParseFixture for boards written in code; it panics on
+// malformed text.
+func MustParseFixture(text string) Fixture {
+	f, err := ParseFixture(text)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+func isFixtureSection(s string) bool {
+	for _, name := range fixtureSections {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Info derives the GameInfo a bot would receive if the fixture were the
+// initial frame.
+func (f Fixture) Info() GameInfo {
+	return NewGameInfo(f.Me, f.Map)
+}
+
+// String formats the fixture with every grid, columns right aligned, so
+// that ParseFixture(f.String()) gives back an equal fixture.
+func (f Fixture) String() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "me %d\n", f.Me)
+	for _, name := range fixtureSections {
+		buf.WriteString(name)
+		buf.WriteByte('\n')
+		for y := 0; y < f.Map.Height; y++ {
+			for x := 0; x < f.Map.Width; x++ {
+				site := f.Map.Contents[y][x]
+				cell := ""
+				switch name {
+				case "owner":
+					cell = "."
+					if !site.IsNeutral() {
+						cell = strconv.Itoa(int(site.Owner))
+					}
+				case "strength":
+					cell = strconv.Itoa(site.Strength)
+				case "production":
+					cell = strconv.Itoa(site.Production)
+				}
+				if x > 0 {
+					buf.WriteByte(' ')
+				}
+				fmt.Fprintf(&buf, "%3s", cell)
+			}
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.String()
+}
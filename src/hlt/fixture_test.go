@@ -0,0 +1,62 @@
+package hlt
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+const canonicalFixture = `me 2
+owner
+  .   1   .
+  2   2   .
+strength
+  0  10 255
+ 30   5   7
+production
+  1   2   3
+  4   0   6
+`
+
+func TestFixtureStringRoundTrip(t *testing.T) {
+	f, err := ParseFixture(canonicalFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := f.String(); got != canonicalFixture {
+		t.Errorf("String() =\n%s\nwant\n%s", got, canonicalFixture)
+	}
+	again, err := ParseFixture(f.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Me != f.Me || !again.Map.Equal(f.Map) {
+		t.Errorf("parsing String() gave a different fixture")
+	}
+}
+
+// The golden frames are written with String, so they must read back to the
+// same text.
+func TestGoldenFramesRoundTrip(t *testing.T) {
+	paths, err := filepath.Glob("../../golden/*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Skip("no golden frames")
+	}
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := ParseFixture(string(data))
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+			continue
+		}
+		if got := f.String(); got != string(data) {
+			t.Errorf("%s: String() does not reproduce the file", path)
+		}
+	}
+}
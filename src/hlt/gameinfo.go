@@ -0,0 +1,101 @@
+package hlt
+
+import (
+	"math"
+)
+
+// GameInfo holds the metadata that stays fixed for a whole game. It is built
+// once from the initial frame and is safe to share between goroutines and
+// frames; accessors hand out copies so callers cannot modify it.
+type GameInfo struct {
+	playerTag     PlayerID
+	width, height int
+	productions   [][]int
+	players       []PlayerID
+	starts        map[PlayerID]Location
+	initial       GameMap
+}
+
+// NewGameInfo derives the game metadata for the player tag from the initial
+// frame of a game. The initial map is kept as seen by that player, so IsMine
+// works on it whatever the frame came from.
+func NewGameInfo(tag PlayerID, initial GameMap) GameInfo {
+	info := GameInfo{
+		playerTag: tag,
+		width:     initial.Width,
+		height:    initial.Height,
+		players:   initial.Players(),
+		starts:    make(map[PlayerID]Location),
+		initial:   initial.Clone(),
+	}
+	info.initial.SetPlayer(tag)
+	info.productions = make([][]int, initial.Height)
+	for y := 0; y < initial.Height; y++ {
+		info.productions[y] = make([]int, initial.Width)
+		for x := 0; x < initial.Width; x++ {
+			site := initial.Contents[y][x]
+			info.productions[y][x] = site.Production
+			if _, ok := info.starts[site.Owner]; !ok && !site.IsNeutral() {
+				info.starts[site.Owner] = NewLocation(x, y)
+			}
+		}
+	}
+	return info
+}
+
+func (g GameInfo) PlayerTag() PlayerID {
+	return g.playerTag
+}
+
+func (g GameInfo) Width() int {
+	return g.width
+}
+
+func (g GameInfo) Height() int {
+	return g.height
+}
+
+func (g GameInfo) Production(loc Location) int {
+	return g.productions[loc.Y][loc.X]
+}
+
+// Productions returns a copy of the production grid indexed [y][x].
+func (g GameInfo) Productions() [][]int {
+	productions := make([][]int, len(g.productions))
+	for y, row := range g.productions {
+		productions[y] = append([]int(nil), row...)
+	}
+	return productions
+}
+
+// InitialMap returns a copy of the frame the game started from.
+func (g GameInfo) InitialMap() GameMap {
+	return g.initial.Clone()
+}
+
+func (g GameInfo) PlayerCount() int {
+	return len(g.players)
+}
+
+func (g GameInfo) Players() []PlayerID {
+	return append([]PlayerID(nil), g.players...)
+}
+
+func (g GameInfo) StartingLocation(p PlayerID) (Location, bool) {
+	loc, ok := g.starts[p]
+	return loc, ok
+}
+
+func (g GameInfo) StartingLocations() map[PlayerID]Location {
+	starts := make(map[PlayerID]Location, len(g.starts))
+	for p, loc := range g.starts {
+		starts[p] = loc
+	}
+	return starts
+}
+
+// TurnLimit is the number of turns the engine plays before ranking players
+// by territory: ten times the square root of the map area.
+func (g GameInfo) TurnLimit() int {
+	return int(10 * math.Sqrt(float64(g.width*g.height)))
+}
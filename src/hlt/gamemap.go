@@ -1,8 +1,10 @@
 package hlt
 
 import (
+	"fmt"
 	"log"
 	"math"
+	"sort"
 	"strconv"
 )
 
@@ -27,15 +29,107 @@ func NewGameMap(width, height int) GameMap {
 	return gameMap
 }
 
+func (m *GameMap) Clone() GameMap {
+	clone := GameMap{
+		Width:    m.Width,
+		Height:   m.Height,
+		Contents: make([][]Site, len(m.Contents)),
+	}
+	for y, row := range m.Contents {
+		clone.Contents[y] = append([]Site(nil), row...)
+	}
+	return clone
+}
+
+// Equal reports whether both maps have the same size and every site has the
+// same owner, strength and production.
+func (m *GameMap) Equal(other GameMap) bool {
+	if m.Width != other.Width || m.Height != other.Height {
+		return false
+	}
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if !sameSite(m.Contents[y][x], other.Contents[y][x]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+type SiteChange struct {
+	Location      Location
+	Before, After Site
+}
+
+func (c SiteChange) String() string {
+	return fmt.Sprintf("(%d,%d) owner %d->%d strength %d->%d", c.Location.X, c.Location.Y,
+		c.Before.Owner, c.After.Owner, c.Before.Strength, c.After.Strength)
+}
+
+// Diff lists the sites whose owner or strength differ in other, in row order.
+// Maps of different dimensions cannot be compared site by site.
+func (m *GameMap) Diff(other GameMap) ([]SiteChange, error) {
+	if m.Width != other.Width || m.Height != other.Height {
+		return nil, fmt.Errorf("hlt: diff of %dx%d map against %dx%d map", m.Width, m.Height, other.Width, other.Height)
+	}
+	changes := make([]SiteChange, 0)
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			before, after := m.Contents[y][x], other.Contents[y][x]
+			if before.Owner != after.Owner || before.Strength != after.Strength {
+				changes = append(changes, SiteChange{
+					Location: NewLocation(x, y),
+					Before:   before,
+					After:    after,
+				})
+			}
+		}
+	}
+	return changes, nil
+}
+
+func sameSite(a, b Site) bool {
+	return a.Owner == b.Owner && a.Strength == b.Strength && a.Production == b.Production
+}
+
 func int_str_array_pop(input []string) (int, []string) {
 	ret, err := strconv.Atoi(input[0])
 	input = input[1:]
 	if err != nil {
-		log.Printf("Whoopse", err)
+		log.Printf("Whoopse: %v", err)
 	}
 	return ret, input
 }
 
+// SetPlayer sets the player whose sites IsMine reports, as a Connection does
+// for the maps it receives. Use it for maps built from fixtures or replays.
+func (m *GameMap) SetPlayer(tag PlayerID) {
+	for y := range m.Contents {
+		for x := range m.Contents[y] {
+			m.Contents[y][x].me = tag
+		}
+	}
+}
+
+// Players returns the distinct non-neutral owners on the map in ascending
+// order. On the initial frame this is every player in the game.
+func (m *GameMap) Players() []PlayerID {
+	seen := make(map[PlayerID]bool)
+	players := make([]PlayerID, 0)
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			owner := m.Contents[y][x].Owner
+			if owner != Neutral && !seen[owner] {
+				seen[owner] = true
+				players = append(players, owner)
+			}
+		}
+	}
+	sort.Slice(players, func(i, j int) bool { return players[i] < players[j] })
+	return players
+}
+
 func (m *GameMap) InBounds(loc Location) bool {
 	return loc.X >= 0 && loc.X < m.Width && loc.Y >= 0 && loc.Y < m.Height
 }
@@ -46,7 +140,7 @@ func (m *GameMap) GetDistance(loc1, loc2 Location) int {
 	if dx > m.Width/2 {
 		dx = m.Width - dx
 	}
-	if dy > m.Width/2 {
+	if dy > m.Height/2 {
 		dy = m.Height - dy
 	}
 	return dx + dy
@@ -0,0 +1,77 @@
+package hlt
+
+import "testing"
+
+func TestCloneDoesNotAlias(t *testing.T) {
+	f, err := ParseFixture(canonicalFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone := f.Map.Clone()
+	if !clone.Equal(f.Map) {
+		t.Fatal("clone differs from the original")
+	}
+	clone.Contents[0][1].Owner = 2
+	clone.Contents[0][1].Strength = 99
+	if site := f.Map.Contents[0][1]; site.Owner != 1 || site.Strength != 10 {
+		t.Errorf("changing the clone changed the original to %+v", site)
+	}
+	if clone.Equal(f.Map) {
+		t.Error("Equal missed a changed site")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	f, err := ParseFixture(canonicalFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	after := f.Map.Clone()
+	after.Contents[0][1].Owner = 2
+	after.Contents[0][1].Strength = 0
+	after.Contents[1][2].Strength = 8
+	after.Contents[1][1].Production = 9
+	changes, err := f.Map.Diff(after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SiteChange{
+		{NewLocation(1, 0), Site{Owner: 1, Strength: 10}, Site{Owner: 2}},
+		{NewLocation(2, 1), Site{Strength: 7}, Site{Strength: 8}},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("Diff = %v, want %v", changes, want)
+	}
+	for i, c := range changes {
+		w := want[i]
+		if c.Location != w.Location || c.Before.Owner != w.Before.Owner || c.Before.Strength != w.Before.Strength ||
+			c.After.Owner != w.After.Owner || c.After.Strength != w.After.Strength {
+			t.Errorf("change %d = %v, want %v", i, c, w)
+		}
+	}
+}
+
+func TestDiffSizeMismatch(t *testing.T) {
+	m := NewGameMap(3, 2)
+	if changes, err := m.Diff(NewGameMap(2, 3)); err == nil {
+		t.Errorf("Diff of different sizes = %v, want an error", changes)
+	}
+}
+
+func TestGetDistanceWraps(t *testing.T) {
+	m := NewGameMap(20, 6)
+	for _, c := range []struct {
+		a, b Location
+		want int
+	}{
+		{NewLocation(0, 0), NewLocation(3, 2), 5},
+		{NewLocation(0, 0), NewLocation(0, 4), 2},
+		{NewLocation(0, 5), NewLocation(0, 1), 2},
+		{NewLocation(1, 0), NewLocation(18, 0), 3},
+		{NewLocation(0, 0), NewLocation(10, 3), 13},
+	} {
+		if got := m.GetDistance(c.a, c.b); got != c.want {
+			t.Errorf("distance %v to %v on 20x6 = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
@@ -23,11 +23,46 @@ const (
 var Directions = []Direction{STILL, NORTH,EAST, SOUTH, WEST}
 var CARDINALS = []Direction{NORTH,EAST, SOUTH, WEST}
 
+var directionNames = []string{"STILL", "N", "E", "S", "W"}
+
+func (d Direction) String() string {
+	if d < 0 || int(d) >= len(directionNames) {
+		return fmt.Sprintf("Direction(%d)", int(d))
+	}
+	return directionNames[d]
+}
+
+// ParseDirection accepts the names String returns.
+func ParseDirection(s string) (Direction, error) {
+	for d, name := range directionNames {
+		if s == name {
+			return Direction(d), nil
+		}
+	}
+	return STILL, fmt.Errorf("unknown direction %q", s)
+}
+
+// PlayerID identifies the owner of a site. The engine numbers players from 1
+// and uses 0 for unowned land.
+type PlayerID int
+
+const Neutral PlayerID = 0
 
 type Site struct {
-	Owner	   int
+	Owner	   PlayerID
 	Strength   int
 	Production int
+	me		   PlayerID
+}
+
+func (s Site) IsNeutral() bool {
+	return s.Owner == Neutral
+}
+
+// IsMine reports whether the site belongs to the player the map was received
+// for. Sites that did not come from a Connection are never mine.
+func (s Site) IsMine() bool {
+	return s.me != Neutral && s.Owner == s.me
 }
 
 type Location struct {
@@ -58,23 +93,52 @@ func (ms MoveSet) serialize() string {
 
 type Connection struct {
 	width, height int
-	PlayerTag	  int
+	PlayerTag	  PlayerID
 	productions   [][]int
 	reader		  *bufio.Reader
 	writer		  io.Writer
+	info		  GameInfo
 }
 
 func (c *Connection) deserializeMap() GameMap {
-	splitString := strings.Split(c.getString(), " ")
+	m, err := parseFrame(c.getString(), c.width, c.height, c.productions, c.PlayerTag)
+	if err != nil {
+		log.Printf("Whoopse: %v", err)
+	}
+	return m
+}
+
+// parseFrame decodes a frame line: run-length encoded (count, owner) pairs
+// followed by one strength per site, both in row order.
+func parseFrame(line string, width, height int, productions [][]int, tag PlayerID) (GameMap, error) {
+	fields := strings.Fields(line)
+	next := func() (int, error) {
+		if len(fields) == 0 {
+			return 0, fmt.Errorf("frame ended early")
+		}
+		i, err := strconv.Atoi(fields[0])
+		fields = fields[1:]
+		return i, err
+	}
 
-	m := NewGameMap(c.width, c.height)
+	m := NewGameMap(width, height)
 
-	var x, y, owner, counter int
+	var x, y int
 	for y != m.Height {
-		counter, splitString = int_str_array_pop(splitString)
-		owner, splitString = int_str_array_pop(splitString)
-		for a := 0; a < counter; a++ {
-			m.Contents[y][x].Owner = owner
+		counter, err := next()
+		if err != nil {
+			return m, err
+		}
+		owner, err := next()
+		if err != nil {
+			return m, err
+		}
+		if counter < 1 {
+			return m, fmt.Errorf("frame has run of %d sites", counter)
+		}
+		for a := 0; a < counter && y != m.Height; a++ {
+			m.Contents[y][x].Owner = PlayerID(owner)
+			m.Contents[y][x].me = tag
 
 			x += 1
 			if x == m.Width {
@@ -86,12 +150,16 @@ func (c *Connection) deserializeMap() GameMap {
 
 	for y := 0; y < m.Height; y++ {
 		for x := 0; x < m.Width; x++ {
-			m.Contents[y][x].Strength, splitString = int_str_array_pop(splitString)
-			m.Contents[y][x].Production = c.productions[y][x]
+			strength, err := next()
+			if err != nil {
+				return m, err
+			}
+			m.Contents[y][x].Strength = strength
+			m.Contents[y][x].Production = productions[y][x]
 		}
 	}
 
-	return m
+	return m, nil
 }
 
 func (c *Connection) sendString(input string) {
@@ -107,7 +175,7 @@ func (c *Connection) getString() string {
 func (c *Connection) getInt() int {
 	i, err := strconv.Atoi(c.getString())
 	if err != nil {
-		log.Printf("Whoopse", err)
+		log.Printf("Whoopse: %v", err)
 	}
 	return i
 }
@@ -130,17 +198,36 @@ func (c *Connection) deserializeProductions() {
 	}
 }
 
-func NewConnection(name string) (Connection, GameMap) {
+// NewConnection reads the initial state of the game and replies with the
+// bot's name straight away.
+func NewConnection(name string) (Connection, GameInfo, GameMap) {
+	conn, info, gameMap := Connect()
+	conn.SendName(name)
+	return conn, info, gameMap
+}
+
+// Connect reads the initial state of the game without replying. The
+// environment's init time runs until SendName, so a bot can prepare in
+// between.
+func Connect() (Connection, GameInfo, GameMap) {
 	conn := Connection{
 		reader: bufio.NewReader(os.Stdin),
 		writer: os.Stdout,
 	}
-	conn.PlayerTag = conn.getInt()
+	conn.PlayerTag = PlayerID(conn.getInt())
 	conn.deserializeMapSize()
 	conn.deserializeProductions()
-	conn.sendString(name)
+	gameMap := conn.deserializeMap()
+	conn.info = NewGameInfo(conn.PlayerTag, gameMap)
+	return conn, conn.info, gameMap
+}
+
+func (c *Connection) SendName(name string) {
+	c.sendString(name)
+}
 
-	return conn, conn.deserializeMap()
+func (c *Connection) Info() GameInfo {
+	return c.info
 }
 
 func (c *Connection) GetFrame() GameMap {
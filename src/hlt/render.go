@@ -0,0 +1,123 @@
+package hlt
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// RenderOptions controls how a GameMap is drawn as text.
+type RenderOptions struct {
+	// Color draws owners as ANSI background colours instead of only owner
+	// characters, for terminals and `less -R`.
+	Color bool
+	// Moves are drawn as arrows in place of the strength digit of their cell.
+	Moves MoveSet
+	// Highlight marks cells with a '*' (or reverse video when colored).
+	Highlight []Location
+}
+
+var moveArrows = map[Direction]byte{
+	NORTH: '^',
+	EAST:  '>',
+	SOUTH: 'v',
+	WEST:  '<',
+}
+
+// ansiOwnerColors are 256-colour palette indices, cycled for more players.
+var ansiOwnerColors = []int{160, 27, 34, 172, 127, 37}
+
+func ownerChar(p PlayerID) byte {
+	switch {
+	case p == Neutral:
+		return '.'
+	case p <= 9:
+		return byte('0' + p)
+	default:
+		return byte('a' + (p-10)%26)
+	}
+}
+
+// strengthDigit scales a strength of 0-255 to a single digit.
+func strengthDigit(strength int) byte {
+	d := strength * 10 / 256
+	if d > 9 {
+		d = 9
+	}
+	if d < 0 {
+		d = 0
+	}
+	return byte('0' + d)
+}
+
+// Render draws the map one row per line. Every cell is three characters: a
+// highlight marker, the owner ('.' for neutral) and the strength as a digit
+// from 0 to 9, or an arrow when the cell has a planned move.
+func (m *GameMap) Render(opts RenderOptions) string {
+	arrows := make(map[Location]byte, len(opts.Moves))
+	for _, mv := range opts.Moves {
+		if a, ok := moveArrows[mv.Direction]; ok {
+			arrows[mv.Location] = a
+		}
+	}
+	highlighted := make(map[Location]bool, len(opts.Highlight))
+	for _, loc := range opts.Highlight {
+		highlighted[loc] = true
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("   ")
+	for x := 0; x < m.Width; x++ {
+		fmt.Fprintf(&buf, "  %d", x%10)
+	}
+	buf.WriteByte('\n')
+	for y := 0; y < m.Height; y++ {
+		fmt.Fprintf(&buf, "%3d", y)
+		for x := 0; x < m.Width; x++ {
+			loc := NewLocation(x, y)
+			site := m.Contents[y][x]
+			marker := byte(' ')
+			if highlighted[loc] {
+				marker = '*'
+			}
+			value := strengthDigit(site.Strength)
+			if a, ok := arrows[loc]; ok {
+				value = a
+			}
+			if opts.Color {
+				buf.WriteByte(marker)
+				buf.WriteString(ansiCellStyle(site.Owner, highlighted[loc]))
+				buf.WriteByte(ownerChar(site.Owner))
+				buf.WriteByte(value)
+				buf.WriteString("\x1b[0m")
+			} else {
+				buf.WriteByte(marker)
+				buf.WriteByte(ownerChar(site.Owner))
+				buf.WriteByte(value)
+			}
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+// ANSIStyle is the escape sequence Render uses for cells of the owner, for
+// callers drawing legends next to a rendered map. Reset with "\x1b[0m".
+func ANSIStyle(owner PlayerID) string {
+	if owner == Neutral {
+		return "\x1b[90m"
+	}
+	color := ansiOwnerColors[int(owner-1)%len(ansiOwnerColors)]
+	return fmt.Sprintf("\x1b[97;48;5;%dm", color)
+}
+
+func ansiCellStyle(owner PlayerID, highlighted bool) string {
+	style := ANSIStyle(owner)
+	if highlighted {
+		style += "\x1b[7m"
+	}
+	return style
+}
+
+func (m GameMap) String() string {
+	return m.Render(RenderOptions{})
+}
@@ -0,0 +1,49 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"io"
+
+	"hlt"
+)
+
+type GIFOptions struct {
+	Options
+	// Delay between frames in hundredths of a second.
+	Delay int
+}
+
+// gifPalette holds the shades Image produces: every player colour at the
+// strengths it can be drawn with, a ramp of the bluish production greys and
+// plain black and white for overlays.
+var gifPalette = func() color.Palette {
+	p := color.Palette{color.Black, color.White}
+	for _, c := range playerColors {
+		for i := 0; i < 28; i++ {
+			p = append(p, shade(c, 0.4+0.6*float64(i)/27))
+		}
+	}
+	for len(p) < 256 {
+		level := uint8(10 + 130*(len(p)-170)/86)
+		p = append(p, color.RGBA{level, level, level + level/6, 255})
+	}
+	return p
+}()
+
+// WriteGIF renders each map as one frame of a looping animated GIF. Frames
+// are mapped onto a palette of the renderer's own colours without dithering
+// so territory stays flat-coloured.
+func WriteGIF(w io.Writer, frames []hlt.GameMap, opts GIFOptions) error {
+	anim := &gif.GIF{}
+	for _, m := range frames {
+		img := Image(m, opts.Options)
+		paletted := image.NewPaletted(img.Bounds(), gifPalette)
+		draw.Draw(paletted, img.Bounds(), img, image.Point{}, draw.Src)
+		anim.Image = append(anim.Image, paletted)
+		anim.Delay = append(anim.Delay, opts.Delay)
+	}
+	return gif.EncodeAll(w, anim)
+}
@@ -0,0 +1,122 @@
+// Package render draws GameMap frames as images using only the standard
+// library image packages.
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+
+	"hlt"
+)
+
+const DefaultScale = 8
+
+// Options controls how a frame is drawn.
+type Options struct {
+	// Scale is the width and height of a cell in pixels. Zero means
+	// DefaultScale.
+	Scale int
+	// Overlays outline individual cells in the given colour, for example
+	// planned moves or cells a test failed on.
+	Overlays map[hlt.Location]color.Color
+}
+
+var playerColors = []color.RGBA{
+	{214, 39, 40, 255},
+	{31, 119, 180, 255},
+	{44, 160, 44, 255},
+	{255, 127, 14, 255},
+	{148, 103, 189, 255},
+	{23, 190, 207, 255},
+}
+
+// PlayerColor is the base colour used for a player's territory.
+func PlayerColor(p hlt.PlayerID) color.RGBA {
+	if p == hlt.Neutral {
+		return color.RGBA{128, 128, 128, 255}
+	}
+	return playerColors[int(p-1)%len(playerColors)]
+}
+
+// Image draws the map with one Scale x Scale block per cell. The cell
+// background brightness shows production; owned cells are filled with the
+// owner's colour, darker when weak and full brightness at strength 255.
+// Neutral cells show their strength as a grey tint over the background.
+func Image(m hlt.GameMap, opts Options) *image.RGBA {
+	scale := opts.Scale
+	if scale <= 0 {
+		scale = DefaultScale
+	}
+	maxProduction := 1
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if p := m.Contents[y][x].Production; p > maxProduction {
+				maxProduction = p
+			}
+		}
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, m.Width*scale, m.Height*scale))
+	inset := 0
+	if scale >= 4 {
+		inset = 1
+	}
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			site := m.Contents[y][x]
+			cell := image.Rect(x*scale, y*scale, (x+1)*scale, (y+1)*scale)
+			level := uint8(20 + 80*site.Production/maxProduction)
+			background := color.RGBA{level, level, level + level/4, 255}
+			fill(img, cell, background)
+
+			inner := cell.Inset(inset)
+			if site.IsNeutral() {
+				fill(img, inner, blend(background, PlayerColor(hlt.Neutral), float64(site.Strength)/510))
+			} else {
+				fill(img, inner, shade(PlayerColor(site.Owner), 0.4+0.6*float64(site.Strength)/255))
+			}
+
+			if c, ok := opts.Overlays[hlt.NewLocation(x, y)]; ok {
+				outline(img, cell, c)
+			}
+		}
+	}
+	return img
+}
+
+func WritePNG(w io.Writer, m hlt.GameMap, opts Options) error {
+	return png.Encode(w, Image(m, opts))
+}
+
+func fill(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+func outline(img *image.RGBA, r image.Rectangle, c color.Color) {
+	for x := r.Min.X; x < r.Max.X; x++ {
+		img.Set(x, r.Min.Y, c)
+		img.Set(x, r.Max.Y-1, c)
+	}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		img.Set(r.Min.X, y, c)
+		img.Set(r.Max.X-1, y, c)
+	}
+}
+
+func shade(c color.RGBA, f float64) color.RGBA {
+	return color.RGBA{uint8(float64(c.R) * f), uint8(float64(c.G) * f), uint8(float64(c.B) * f), 255}
+}
+
+// blend mixes f of b into a.
+func blend(a, b color.RGBA, f float64) color.RGBA {
+	mix := func(x, y uint8) uint8 {
+		return uint8(float64(x)*(1-f) + float64(y)*f)
+	}
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), 255}
+}
@@ -0,0 +1,213 @@
+// Package replay loads the .hlt replay files written by the Halite engine and
+// protocol captures taken from a bot's standard input.
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"hlt"
+)
+
+type Replay struct {
+	Version     int
+	Width       int
+	Height      int
+	PlayerNames []string
+	Productions [][]int
+	Frames      []hlt.GameMap
+	// Moves[f] holds the direction every cell was ordered to move between
+	// Frames[f] and Frames[f+1], indexed [y][x].
+	Moves [][][]hlt.Direction
+}
+
+// file mirrors the engine's JSON layout. Frames are [frame][y][x] pairs of
+// owner and strength.
+type file struct {
+	Version     int          `json:"version"`
+	Width       int          `json:"width"`
+	Height      int          `json:"height"`
+	NumPlayers  int          `json:"num_players"`
+	NumFrames   int          `json:"num_frames"`
+	PlayerNames []string     `json:"player_names"`
+	Productions [][]int      `json:"productions"`
+	Frames      [][][][2]int `json:"frames"`
+	Moves       [][][]int    `json:"moves"`
+}
+
+func Load(path string) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Read decodes an engine replay, or a protocol capture when the input does
+// not start with a JSON object. Captures have no moves and name players by
+// number.
+func Read(r io.Reader) (*Replay, error) {
+	br := bufio.NewReader(r)
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("replay: %v", err)
+		}
+		if b == ' ' || b == '\t' || b == '\r' || b == '\n' {
+			continue
+		}
+		br.UnreadByte()
+		if b != '{' {
+			return readCapture(br)
+		}
+		break
+	}
+
+	var raw file
+	if err := json.NewDecoder(br).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("replay: %v", err)
+	}
+	if len(raw.Productions) != raw.Height {
+		return nil, fmt.Errorf("replay: %d production rows for height %d", len(raw.Productions), raw.Height)
+	}
+	for y, row := range raw.Productions {
+		if len(row) != raw.Width {
+			return nil, fmt.Errorf("replay: production row %d has %d cells, want %d", y, len(row), raw.Width)
+		}
+	}
+	if len(raw.Frames) == 0 {
+		return nil, fmt.Errorf("replay: no frames")
+	}
+
+	rep := &Replay{
+		Version:     raw.Version,
+		Width:       raw.Width,
+		Height:      raw.Height,
+		PlayerNames: raw.PlayerNames,
+		Productions: raw.Productions,
+		Frames:      make([]hlt.GameMap, len(raw.Frames)),
+		Moves:       make([][][]hlt.Direction, len(raw.Moves)),
+	}
+	for f, frame := range raw.Frames {
+		if len(frame) != raw.Height {
+			return nil, fmt.Errorf("replay: frame %d has %d rows, want %d", f, len(frame), raw.Height)
+		}
+		m := hlt.NewGameMap(raw.Width, raw.Height)
+		for y, row := range frame {
+			if len(row) != raw.Width {
+				return nil, fmt.Errorf("replay: frame %d row %d has %d cells, want %d", f, y, len(row), raw.Width)
+			}
+			for x, cell := range row {
+				m.Contents[y][x].Owner = hlt.PlayerID(cell[0])
+				m.Contents[y][x].Strength = cell[1]
+				m.Contents[y][x].Production = raw.Productions[y][x]
+			}
+		}
+		rep.Frames[f] = m
+	}
+	for f, frame := range raw.Moves {
+		rep.Moves[f] = make([][]hlt.Direction, len(frame))
+		for y, row := range frame {
+			rep.Moves[f][y] = make([]hlt.Direction, len(row))
+			for x, d := range row {
+				rep.Moves[f][y][x] = hlt.Direction(d)
+			}
+		}
+	}
+	return rep, nil
+}
+
+func readCapture(r io.Reader) (*Replay, error) {
+	info, frames, err := hlt.ReadCapture(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("replay: capture has no frames")
+	}
+	rep := &Replay{
+		Width:       info.Width(),
+		Height:      info.Height(),
+		Productions: info.Productions(),
+		Frames:      frames,
+	}
+	players := info.Players()
+	for p := hlt.PlayerID(1); len(players) > 0 && p <= players[len(players)-1]; p++ {
+		name := fmt.Sprintf("player %d", p)
+		if p == info.PlayerTag() {
+			name += " (me)"
+		}
+		rep.PlayerNames = append(rep.PlayerNames, name)
+	}
+	return rep, nil
+}
+
+func (r *Replay) PlayerCount() int {
+	return len(r.PlayerNames)
+}
+
+// Name returns the name of a player, or "neutral".
+func (r *Replay) Name(p hlt.PlayerID) string {
+	if p == hlt.Neutral || int(p) > len(r.PlayerNames) {
+		return "neutral"
+	}
+	return r.PlayerNames[p-1]
+}
+
+// Info builds the GameInfo the engine would have sent to the given player.
+func (r *Replay) Info(tag hlt.PlayerID) (hlt.GameInfo, error) {
+	if len(r.Frames) == 0 {
+		return hlt.GameInfo{}, fmt.Errorf("replay: no frames")
+	}
+	return hlt.NewGameInfo(tag, r.Frames[0]), nil
+}
+
+// MoveSet returns the moves a player made between frame f and f+1.
+func (r *Replay) MoveSet(f int, p hlt.PlayerID) hlt.MoveSet {
+	var moves hlt.MoveSet
+	if f >= len(r.Moves) {
+		return moves
+	}
+	frame := r.Frames[f]
+	for y, row := range r.Moves[f] {
+		for x, d := range row {
+			if frame.Contents[y][x].Owner == p {
+				moves = append(moves, hlt.Move{Location: hlt.NewLocation(x, y), Direction: d})
+			}
+		}
+	}
+	return moves
+}
+
+type Stats struct {
+	Territory  int
+	Strength   int
+	Production int
+}
+
+// Stats totals each player's cells, strength and production in frame f,
+// indexed by player ID. Index 0 holds the neutral totals.
+func (r *Replay) Stats(f int) []Stats {
+	return FrameStats(r.Frames[f], r.PlayerCount())
+}
+
+func FrameStats(m hlt.GameMap, players int) []Stats {
+	stats := make([]Stats, players+1)
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			site := m.Contents[y][x]
+			if int(site.Owner) >= len(stats) {
+				continue
+			}
+			s := &stats[site.Owner]
+			s.Territory++
+			s.Strength += site.Strength
+			s.Production += site.Production
+		}
+	}
+	return stats
+}
@@ -0,0 +1,24 @@
+package replay
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadRejectsMalformed(t *testing.T) {
+	for name, text := range map[string]string{
+		"short production row": `{"width":2,"height":1,"productions":[[1]],"frames":[[[[0,1],[0,1]]]]}`,
+		"no frames":            `{"width":1,"height":1,"productions":[[1]],"frames":[]}`,
+		"short frame row":      `{"width":2,"height":1,"productions":[[1,1]],"frames":[[[[0,1]]]]}`,
+	} {
+		if _, err := Read(strings.NewReader(text)); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
+
+func TestInfoWithoutFrames(t *testing.T) {
+	if _, err := (&Replay{}).Info(1); err == nil {
+		t.Error("no error for a replay without frames")
+	}
+}
@@ -0,0 +1,177 @@
+// Package scenario checks a bot's per-cell decisions against small boards.
+//
+// A scenario file is a hlt fixture with extra lines saying what the bot
+// should do:
+//
+//	# Attack the only enemy neighbour.
+//	expect 1 1 E
+//	moved 2 1 W
+//	me 1
+//	owner
+//	. . .
+//	. 1 2
+//	...
+//
+// "expect x y DIRS" lists the directions, separated by commas, the cell at
+// (x, y) may choose. "moved x y DIR" records a move from the previous turn
+// for bots that remember them. The comment lines at the top of the file are
+// the description.
+package scenario
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"hlt"
+)
+
+type Expectation struct {
+	Location   hlt.Location
+	Directions []hlt.Direction
+}
+
+func (e Expectation) allows(d hlt.Direction) bool {
+	for _, allowed := range e.Directions {
+		if d == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+type Scenario struct {
+	Name        string
+	Description string
+	Fixture     hlt.Fixture
+	Expect      []Expectation
+	Previous    hlt.MoveSet
+}
+
+// Decider prepares a bot for a scenario and returns its decision function
+// for single cells.
+type Decider func(s Scenario) func(loc hlt.Location) hlt.Direction
+
+// Repeats is how often every expectation is checked, so that a random
+// choice between allowed and disallowed directions is caught.
+const Repeats = 25
+
+func Parse(name, text string) (Scenario, error) {
+	s := Scenario{Name: name}
+	var board, description []string
+	for n, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "expect", "moved":
+			if len(fields) != 4 {
+				return s, fmt.Errorf("%s:%d: want \"%s x y direction\"", name, n+1, fields[0])
+			}
+			x, errX := strconv.Atoi(fields[1])
+			y, errY := strconv.Atoi(fields[2])
+			if errX != nil || errY != nil {
+				return s, fmt.Errorf("%s:%d: bad location %s %s", name, n+1, fields[1], fields[2])
+			}
+			var dirs []hlt.Direction
+			for _, dn := range strings.Split(fields[3], ",") {
+				d, err := hlt.ParseDirection(dn)
+				if err != nil {
+					return s, fmt.Errorf("%s:%d: %v", name, n+1, err)
+				}
+				dirs = append(dirs, d)
+			}
+			loc := hlt.NewLocation(x, y)
+			if fields[0] == "expect" {
+				s.Expect = append(s.Expect, Expectation{Location: loc, Directions: dirs})
+			} else if len(dirs) != 1 {
+				return s, fmt.Errorf("%s:%d: a move has one direction", name, n+1)
+			} else {
+				s.Previous = append(s.Previous, hlt.Move{Location: loc, Direction: dirs[0]})
+			}
+		default:
+			if strings.HasPrefix(fields[0], "#") && len(board) == len(description) {
+				description = append(description, strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "#")))
+			}
+			board = append(board, line)
+		}
+	}
+	s.Description = strings.Join(description, " ")
+	if len(s.Expect) == 0 {
+		return s, fmt.Errorf("%s: no expectations", name)
+	}
+	f, err := hlt.ParseFixture(strings.Join(board, "\n"))
+	if err != nil {
+		return s, fmt.Errorf("%s: %v", name, err)
+	}
+	s.Fixture = f
+	return s, nil
+}
+
+// LoadDir parses every *.txt file in dir, in name order.
+func LoadDir(dir string) ([]Scenario, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	scenarios := make([]Scenario, 0, len(paths))
+	for _, path := range paths {
+		text, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		s, err := Parse(strings.TrimSuffix(filepath.Base(path), ".txt"), string(text))
+		if err != nil {
+			return nil, err
+		}
+		scenarios = append(scenarios, s)
+	}
+	return scenarios, nil
+}
+
+// Run checks every scenario, writes a report with the board of each failure
+// to out and returns the number of failed scenarios.
+func Run(scenarios []Scenario, decider Decider, out io.Writer) int {
+	failed := 0
+	for _, s := range scenarios {
+		decide := decider(s)
+		var wrong []hlt.Move
+		for _, e := range s.Expect {
+			for i := 0; i < Repeats; i++ {
+				if d := decide(e.Location); !e.allows(d) {
+					wrong = append(wrong, hlt.Move{Location: e.Location, Direction: d})
+					break
+				}
+			}
+		}
+		if len(wrong) == 0 {
+			fmt.Fprintf(out, "ok   %s\n", s.Name)
+			continue
+		}
+		failed++
+		fmt.Fprintf(out, "FAIL %s: %s\n", s.Name, s.Description)
+		var highlight []hlt.Location
+		for _, mv := range wrong {
+			fmt.Fprintf(out, "     (%d,%d) moved %v, want %v\n", mv.Location.X, mv.Location.Y, mv.Direction, s.expected(mv.Location))
+			highlight = append(highlight, mv.Location)
+		}
+		fmt.Fprint(out, s.Fixture.Map.Render(hlt.RenderOptions{Moves: wrong, Highlight: highlight}))
+	}
+	fmt.Fprintf(out, "%d/%d scenarios passed\n", len(scenarios)-failed, len(scenarios))
+	return failed
+}
+
+func (s Scenario) expected(loc hlt.Location) []hlt.Direction {
+	for _, e := range s.Expect {
+		if e.Location == loc {
+			return e.Directions
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package sim
+
+import (
+	"fmt"
+	"math/rand"
+
+	"hlt"
+)
+
+// Generate makes a fair map for the given number of players: one randomly
+// generated tile is repeated once per player, with every player starting
+// in the middle of their copy. The width and height must split into that
+// many equal tiles.
+func Generate(width, height, players int, seed int64) (hlt.GameMap, error) {
+	cols, rows := tiling(width, height, players)
+	if cols == 0 {
+		return hlt.GameMap{}, fmt.Errorf("sim: %dx%d map does not split into %d equal tiles", width, height, players)
+	}
+	tw, th := width/cols, height/rows
+	rng := rand.New(rand.NewSource(seed))
+
+	// Smooth white noise into blobs of high production, then derive
+	// strengths that make rich land more expensive to take.
+	noise := make([][]float64, th)
+	for y := range noise {
+		noise[y] = make([]float64, tw)
+		for x := range noise[y] {
+			noise[y][x] = rng.Float64()
+		}
+	}
+	for pass := 0; pass < 3; pass++ {
+		noise = blur(noise)
+	}
+	lo, hi := noise[0][0], noise[0][0]
+	for _, row := range noise {
+		for _, v := range row {
+			if v < lo {
+				lo = v
+			}
+			if v > hi {
+				hi = v
+			}
+		}
+	}
+
+	m := hlt.NewGameMap(width, height)
+	tile := make([][]hlt.Site, th)
+	for y := range tile {
+		tile[y] = make([]hlt.Site, tw)
+		for x := range tile[y] {
+			v := 0.0
+			if hi > lo {
+				v = (noise[y][x] - lo) / (hi - lo)
+			}
+			production := 1 + int(v*v*11+0.5)
+			strength := int(v*180) + 20 + rng.Intn(40)
+			if strength > maxStrength {
+				strength = maxStrength
+			}
+			tile[y][x] = hlt.Site{Production: production, Strength: strength}
+		}
+	}
+	for ty := 0; ty < rows; ty++ {
+		for tx := 0; tx < cols; tx++ {
+			for y := 0; y < th; y++ {
+				for x := 0; x < tw; x++ {
+					m.Contents[ty*th+y][tx*tw+x] = tile[y][x]
+				}
+			}
+			m.Contents[ty*th+th/2][tx*tw+tw/2].Owner = hlt.PlayerID(ty*cols + tx + 1)
+		}
+	}
+	return m, nil
+}
+
+// tiling picks the column and row counts for players tiles, preferring
+// square tiles, or returns 0, 0 if none fits.
+func tiling(width, height, players int) (cols, rows int) {
+	best := -1.0
+	for c := 1; c <= players; c++ {
+		if players%c != 0 {
+			continue
+		}
+		r := players / c
+		if width%c != 0 || height%r != 0 {
+			continue
+		}
+		ratio := float64(width/c) / float64(height/r)
+		if ratio > 1 {
+			ratio = 1 / ratio
+		}
+		if ratio > best {
+			best, cols, rows = ratio, c, r
+		}
+	}
+	return cols, rows
+}
+
+// blur averages every cell with its wrapped neighbours.
+func blur(grid [][]float64) [][]float64 {
+	h, w := len(grid), len(grid[0])
+	out := make([][]float64, h)
+	for y := range out {
+		out[y] = make([]float64, w)
+		for x := range out[y] {
+			sum := 0.0
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					sum += grid[(y+dy+h)%h][(x+dx+w)%w]
+				}
+			}
+			out[y][x] = sum / 9
+		}
+	}
+	return out
+}
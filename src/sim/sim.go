@@ -0,0 +1,240 @@
+// Package sim plays Halite games in process, following the engine's rules
+// for movement, production and combat, so bots can be compared and tuned
+// without the external environment.
+package sim
+
+import (
+	"sort"
+
+	"hlt"
+)
+
+const maxStrength = 255
+
+// Player is anything that can play a game; *bot.Bot is one.
+type Player interface {
+	Init(info hlt.GameInfo)
+	Turn(m hlt.GameMap) hlt.MoveSet
+}
+
+// Step applies one turn of moves to a map and returns the next frame. Moves
+// of sites a player does not own are ignored, and owned sites without a move
+// stay still. The input map is not modified.
+func Step(m hlt.GameMap, moves map[hlt.PlayerID]hlt.MoveSet) hlt.GameMap {
+	next := m.Clone()
+	ordered := make(map[hlt.Location]hlt.Direction)
+	for p, ms := range moves {
+		for _, mv := range ms {
+			if m.InBounds(mv.Location) && m.Contents[mv.Location.Y][mv.Location.X].Owner == p && p != hlt.Neutral {
+				ordered[mv.Location] = mv.Direction
+			}
+		}
+	}
+
+	// Lift every player's pieces off the board, moved and merged, leaving a
+	// zero strength piece behind on sites that were vacated.
+	pieces := make(map[hlt.PlayerID]map[hlt.Location]int)
+	add := func(p hlt.PlayerID, loc hlt.Location, strength int) {
+		if pieces[p] == nil {
+			pieces[p] = make(map[hlt.Location]int)
+		}
+		s := pieces[p][loc] + strength
+		if s > maxStrength {
+			s = maxStrength
+		}
+		pieces[p][loc] = s
+	}
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			site := &next.Contents[y][x]
+			if site.IsNeutral() {
+				continue
+			}
+			loc := hlt.NewLocation(x, y)
+			d := ordered[loc]
+			strength := site.Strength
+			if d == hlt.STILL {
+				strength += site.Production
+				if strength > maxStrength {
+					strength = maxStrength
+				}
+			}
+			add(site.Owner, m.GetLocation(loc, d), strength)
+			if d != hlt.STILL {
+				add(site.Owner, loc, 0)
+			}
+			site.Owner = hlt.Neutral
+			site.Strength = 0
+		}
+	}
+
+	// Every piece damages enemy pieces on its own and the adjacent sites by
+	// its strength; neutral sites only fight pieces standing on them.
+	injure := make(map[hlt.PlayerID]map[hlt.Location]int)
+	neutralDamage := make(map[hlt.Location]int)
+	for a, own := range pieces {
+		for loc, strength := range own {
+			for b, theirs := range pieces {
+				if b == a {
+					continue
+				}
+				for _, d := range hlt.Directions {
+					target := m.GetLocation(loc, d)
+					if _, ok := theirs[target]; ok {
+						if injure[b] == nil {
+							injure[b] = make(map[hlt.Location]int)
+						}
+						injure[b][target] += strength
+					}
+				}
+			}
+			if neutral := next.Contents[loc.Y][loc.X].Strength; neutral > 0 {
+				if injure[a] == nil {
+					injure[a] = make(map[hlt.Location]int)
+				}
+				injure[a][loc] += neutral
+				neutralDamage[loc] += strength
+			}
+		}
+	}
+	for p, own := range pieces {
+		for loc, strength := range own {
+			if damage, ok := injure[p][loc]; ok {
+				if damage >= strength {
+					delete(own, loc)
+				} else {
+					own[loc] = strength - damage
+				}
+			}
+		}
+	}
+	for loc, damage := range neutralDamage {
+		site := &next.Contents[loc.Y][loc.X]
+		site.Strength -= damage
+		if site.Strength < 0 {
+			site.Strength = 0
+		}
+	}
+	for p, own := range pieces {
+		for loc, strength := range own {
+			site := &next.Contents[loc.Y][loc.X]
+			site.Owner = p
+			site.Strength = strength
+		}
+	}
+	return next
+}
+
+type Result struct {
+	// Rank of each player in the order they were passed to Play, 1 is the
+	// winner.
+	Rank []int
+	// Territory each player held at the end.
+	Territory []int
+	Turns     int
+	// Frames holds every frame when Game.Record is set.
+	Frames []hlt.GameMap
+}
+
+type Game struct {
+	Map     hlt.GameMap
+	Players []Player
+	// Turns overrides the engine's turn limit when positive.
+	Turns  int
+	Record bool
+}
+
+// Play runs the game to the turn limit or until one player is left. Player
+// i plays as PlayerID i+1 and sees every frame from its own perspective.
+// Eliminated players rank below survivors, later eliminations higher; the
+// survivors are ranked by territory, then total strength.
+func (g Game) Play() Result {
+	m := g.Map.Clone()
+	n := len(g.Players)
+	limit := g.Turns
+	if limit <= 0 {
+		limit = hlt.NewGameInfo(1, m).TurnLimit()
+	}
+	for i, p := range g.Players {
+		p.Init(hlt.NewGameInfo(hlt.PlayerID(i+1), m))
+	}
+
+	res := Result{Rank: make([]int, n), Territory: make([]int, n)}
+	eliminated := make([]int, n)
+	if g.Record {
+		res.Frames = append(res.Frames, m)
+	}
+	for turn := 1; turn <= limit; turn++ {
+		moves := make(map[hlt.PlayerID]hlt.MoveSet, n)
+		for i, p := range g.Players {
+			if eliminated[i] != 0 {
+				continue
+			}
+			view := m.Clone()
+			view.SetPlayer(hlt.PlayerID(i + 1))
+			moves[hlt.PlayerID(i+1)] = p.Turn(view)
+		}
+		m = Step(m, moves)
+		res.Turns = turn
+		if g.Record {
+			res.Frames = append(res.Frames, m)
+		}
+
+		territory, _ := tally(m, n)
+		alive := 0
+		for i := range g.Players {
+			if eliminated[i] == 0 && territory[i] == 0 {
+				eliminated[i] = turn
+			}
+			if eliminated[i] == 0 {
+				alive++
+			}
+		}
+		if alive <= 1 {
+			break
+		}
+	}
+
+	territory, strength := tally(m, n)
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	survived := func(i int) int {
+		if eliminated[i] == 0 {
+			return res.Turns + 1
+		}
+		return eliminated[i]
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		i, j := order[a], order[b]
+		if survived(i) != survived(j) {
+			return survived(i) > survived(j)
+		}
+		if territory[i] != territory[j] {
+			return territory[i] > territory[j]
+		}
+		return strength[i] > strength[j]
+	})
+	for rank, i := range order {
+		res.Rank[i] = rank + 1
+	}
+	res.Territory = territory
+	return res
+}
+
+func tally(m hlt.GameMap, players int) (territory, strength []int) {
+	territory = make([]int, players)
+	strength = make([]int, players)
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			site := m.Contents[y][x]
+			if site.IsNeutral() || int(site.Owner) > players {
+				continue
+			}
+			territory[site.Owner-1]++
+			strength[site.Owner-1] += site.Strength
+		}
+	}
+	return territory, strength
+}
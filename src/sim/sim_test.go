@@ -0,0 +1,229 @@
+package sim
+
+import (
+	"hlt"
+	"testing"
+)
+
+func checkStep(t *testing.T, before string, moves map[hlt.PlayerID]hlt.MoveSet, after string) {
+	t.Helper()
+	b, want := hlt.MustParseFixture(before), hlt.MustParseFixture(after)
+	orig := b.Map.Clone()
+	got := hlt.Fixture{Me: want.Me, Map: Step(b.Map, moves)}
+	if !got.Map.Equal(want.Map) {
+		t.Errorf("Step gave\n%s\nwant\n%s", got, want)
+	}
+	if !b.Map.Equal(orig) {
+		t.Error("Step modified its input")
+	}
+}
+
+func move(x, y int, d hlt.Direction) hlt.Move {
+	return hlt.Move{Location: hlt.NewLocation(x, y), Direction: d}
+}
+
+func TestStepProducesOnlyWhenStill(t *testing.T) {
+	checkStep(t, `
+me 1
+owner
+ 1  1  .  .
+strength
+10 20  0  0
+production
+ 3  4  0  0
+`, map[hlt.PlayerID]hlt.MoveSet{1: {move(0, 0, hlt.STILL), move(1, 0, hlt.EAST)}}, `
+me 1
+owner
+ 1  1  1  .
+strength
+13  0 20  0
+production
+ 3  4  0  0
+`)
+}
+
+func TestStepCapsStrength(t *testing.T) {
+	checkStep(t, `
+me 1
+owner
+  1   1   1  .
+strength
+200 100 254  0
+production
+  0   0   5  0
+`, map[hlt.PlayerID]hlt.MoveSet{1: {move(0, 0, hlt.EAST)}}, `
+me 1
+owner
+  1   1   1  .
+strength
+  0 255 255  0
+production
+  0   0   5  0
+`)
+}
+
+func TestStepNeutralDamage(t *testing.T) {
+	// A piece weaker than the neutral site it moves onto dies and leaves a
+	// dent; the site it left stays ours at 0 strength, and the neutral does
+	// not fight the pieces next to it.
+	checkStep(t, `
+me 1
+owner
+ 1  .  .
+strength
+10 15  0
+production
+ 1  0  0
+`, map[hlt.PlayerID]hlt.MoveSet{1: {move(0, 0, hlt.EAST)}}, `
+me 1
+owner
+ 1  .  .
+strength
+ 0  5  0
+production
+ 1  0  0
+`)
+	checkStep(t, `
+me 1
+owner
+ 1  .  .
+strength
+20 15  0
+`, map[hlt.PlayerID]hlt.MoveSet{1: {move(0, 0, hlt.EAST)}}, `
+me 1
+owner
+ 1  1  .
+strength
+ 0  5  0
+`)
+}
+
+func TestStepAdjacentCombat(t *testing.T) {
+	checkStep(t, `
+me 1
+owner
+ 1  2  .  .  .  .
+strength
+50 30  0  0  0  0
+`, nil, `
+me 1
+owner
+ 1  .  .  .  .  .
+strength
+20  0  0  0  0  0
+`)
+}
+
+func TestStepSameSiteCombat(t *testing.T) {
+	// Both pieces land on the same site and the stronger survives. Each
+	// also kills the 0 strength piece the other left behind.
+	// The map is three rows high so that north and south are other sites.
+	checkStep(t, `
+me 1
+owner
+ .  .  .  .  .  .  .
+ .  1  .  2  .  .  .
+ .  .  .  .  .  .  .
+strength
+ 0  0  0  0  0  0  0
+ 0 40  0 25  0  0  0
+ 0  0  0  0  0  0  0
+`, map[hlt.PlayerID]hlt.MoveSet{
+		1: {move(1, 1, hlt.EAST)},
+		2: {move(3, 1, hlt.WEST)},
+	}, `
+me 1
+owner
+ .  .  .  .  .  .  .
+ .  .  1  .  .  .  .
+ .  .  .  .  .  .  .
+strength
+ 0  0  0  0  0  0  0
+ 0  0 15  0  0  0  0
+ 0  0  0  0  0  0  0
+`)
+}
+
+func TestStepIgnoresOthersSites(t *testing.T) {
+	checkStep(t, `
+me 1
+owner
+ 1  .  2  .  .
+strength
+10  0 10  0  0
+`, map[hlt.PlayerID]hlt.MoveSet{1: {move(2, 0, hlt.WEST), move(9, 9, hlt.EAST)}}, `
+me 1
+owner
+ 1  .  2  .  .
+strength
+10  0 10  0  0
+`)
+}
+
+// scripted plays the moves given for each turn, counting from 1, and keeps
+// the GameInfo it was given.
+type scripted struct {
+	moves map[int]hlt.MoveSet
+	turn  int
+	info  hlt.GameInfo
+}
+
+func (s *scripted) Init(info hlt.GameInfo) {
+	s.info = info
+}
+
+func (s *scripted) Turn(m hlt.GameMap) hlt.MoveSet {
+	s.turn++
+	return s.moves[s.turn]
+}
+
+func TestPlayRanks(t *testing.T) {
+	// Player 2 dies to player 1 on turn 1 and player 4 to player 3 on turn
+	// 2; player 3 ends with more territory than player 1.
+	f := hlt.MustParseFixture(`
+me 1
+owner
+  1  2  .   3  .  4  .  .
+strength
+100 10  0 200  0  5  0  0
+`)
+	players := []*scripted{{}, {}, {moves: map[int]hlt.MoveSet{2: {move(3, 0, hlt.EAST)}}}, {}}
+	g := Game{Map: f.Map, Turns: 3}
+	for _, p := range players {
+		g.Players = append(g.Players, p)
+	}
+	res := g.Play()
+	if res.Turns != 3 {
+		t.Errorf("played %d turns, want 3", res.Turns)
+	}
+	wantRank, wantTerritory := []int{2, 4, 1, 3}, []int{1, 0, 2, 0}
+	for i := range players {
+		if res.Rank[i] != wantRank[i] || res.Territory[i] != wantTerritory[i] {
+			t.Errorf("ranks %v territory %v, want %v and %v", res.Rank, res.Territory, wantRank, wantTerritory)
+			break
+		}
+	}
+	for i, p := range players {
+		start, ok := p.info.StartingLocation(hlt.PlayerID(i + 1))
+		if !ok {
+			t.Fatalf("player %d has no start", i+1)
+		}
+		if m := p.info.InitialMap(); !m.Contents[start.Y][start.X].IsMine() {
+			t.Errorf("player %d's initial map does not mark its start as its own", i+1)
+		}
+	}
+}
+
+func TestPlayStopsWithOneSurvivor(t *testing.T) {
+	f := hlt.MustParseFixture(`
+me 1
+owner
+  1  2  .  .
+strength
+100 10  0  0
+`)
+	res := Game{Map: f.Map, Players: []Player{&scripted{}, &scripted{}}, Turns: 10}.Play()
+	if res.Turns != 1 || res.Rank[0] != 1 || res.Rank[1] != 2 {
+		t.Errorf("played %d turns with ranks %v, want 1 turn and [1 2]", res.Turns, res.Rank)
+	}
+}